@@ -49,6 +49,16 @@ func updateRemoteSecret(prev, curr *v1.Secret) (changed bool) {
 		changed = true
 	}
 
+	if expiration, ok := curr.Annotations[tokenExpirationAnnotationKey]; !ok {
+		if _, ok := prev.Annotations[tokenExpirationAnnotationKey]; ok {
+			delete(prev.Annotations, tokenExpirationAnnotationKey)
+			changed = true
+		}
+	} else if prev.Annotations[tokenExpirationAnnotationKey] != expiration {
+		prev.Annotations[tokenExpirationAnnotationKey] = expiration
+		changed = true
+	}
+
 	if prev.Labels[secretcontroller.MultiClusterSecretLabel] != "true" {
 		prev.Labels[secretcontroller.MultiClusterSecretLabel] = "true"
 		changed = true
@@ -24,6 +24,9 @@ import (
 // TODO(ayj) - add to istio.io/api/annotations
 const clusterContextAnnotationKey = "istio.io/clusterContext"
 
+// tokenExpirationAnnotationKey is set on remote secrets whose bearer token expires, to the RFC 3339 expiration time.
+const tokenExpirationAnnotationKey = "istio.io/tokenExpiration"
+
 // KubeOptions contains kubernetes options common to all commands.
 type KubeOptions struct {
 	Kubeconfig string
@@ -16,10 +16,14 @@ package multicluster
 
 import (
 	"bytes"
+	"encoding/base64"
+	stdjson "encoding/json"
 	"fmt"
 	"io"
 	"os"
+	"sort"
 	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
@@ -95,6 +99,11 @@ istioctl --Kubeconfig=c0.yaml x create-remote-secret --name c0 \
 # Create a secret access a remote cluster with an auth plugin
 istioctl --Kubeconfig=c0.yaml x create-remote-secret --name c0 --auth-type=plugin --auth-plugin-name=gcp \
     | kubectl -n istio-system --Kubeconfig=c1.yaml apply -f -
+
+# Create a secret to access a remote cluster with an exec credential plugin
+istioctl --Kubeconfig=c0.yaml x create-remote-secret --name c0 \
+    --exec-command=aws-iam-authenticator --exec-arg=token --exec-arg=-i --exec-arg=c0 \
+    | kubectl -n istio-system --Kubeconfig=c1.yaml apply -f -
 `,
 		Args: cobra.NoArgs,
 		RunE: func(c *cobra.Command, args []string) error {
@@ -175,6 +184,14 @@ func createPluginKubeconfig(caData []byte, context, server string, authProviderC
 	return c
 }
 
+func createExecPluginKubeconfig(caData []byte, context, server string, execConfig *api.ExecConfig) *api.Config {
+	c := createBaseKubeconfig(caData, context, server)
+	c.AuthInfos[context] = &api.AuthInfo{
+		Exec: execConfig,
+	}
+	return c
+}
+
 func createRemoteSecretFromPlugin(
 	tokenSecret *v1.Secret,
 	context, server, clusterName string,
@@ -192,6 +209,123 @@ func createRemoteSecretFromPlugin(
 	return createRemoteServiceAccountSecret(kubeconfig, clusterName, context)
 }
 
+func createRemoteSecretFromExecPlugin(
+	tokenSecret *v1.Secret,
+	context, server, clusterName string,
+	execConfig *api.ExecConfig,
+) (*v1.Secret, error) {
+	caData, ok := tokenSecret.Data[v1.ServiceAccountRootCAKey]
+	if !ok {
+		return nil, errMissingRootCAKey
+	}
+
+	// Create a Kubeconfig to access the remote cluster using the exec credential plugin.
+	kubeconfig := createExecPluginKubeconfig(caData, context, server, execConfig)
+
+	// Encode the Kubeconfig in a secret that can be loaded by Istio to dynamically discover and access the remote cluster.
+	return createRemoteServiceAccountSecret(kubeconfig, clusterName, context)
+}
+
+const (
+	// ExecAuthPluginName selects a client-go exec credential plugin instead of a legacy auth-provider plugin.
+	ExecAuthPluginName = "exec"
+
+	// Keys of the exec credential plugin settings in the auth plugin configuration. All other keys are passed to the
+	// plugin as environment variables.
+	execPluginCommandKey    = "command"
+	execPluginArgsKey       = "args"
+	execPluginAPIVersionKey = "apiVersion"
+
+	// default client authentication API version of exec credential plugins.
+	defaultExecPluginAPIVersion = "client.authentication.k8s.io/v1alpha1"
+)
+
+// SupportedAuthPlugins is the set of authenticator plugins known to be usable by the Istio control plane when it
+// consumes a remote secret. Plugins outside of this set produce a warning, as the resulting secret is likely unusable.
+// Programs embedding a control plane with additional plugins may extend it.
+var SupportedAuthPlugins = map[string]bool{
+	"azure":            true,
+	"gcp":              true,
+	"oidc":             true,
+	"openstack":        true,
+	ExecAuthPluginName: true,
+}
+
+var errMissingAuthPluginName = fmt.Errorf("--auth-plugin-name must be set with --auth-type=%v", RemoteSecretAuthTypePlugin)
+
+// validateAuthPluginName returns an error if the plugin name is missing and a warning if the plugin is not known to
+// be supported.
+func validateAuthPluginName(name string) (warning string, err error) {
+	if name == "" {
+		return "", errMissingAuthPluginName
+	}
+	if !SupportedAuthPlugins[name] {
+		return fmt.Sprintf("auth plugin %q is not known to be supported by Istio, the remote secret may be unusable", name), nil
+	}
+	return "", nil
+}
+
+// execConfigForRemoteSecret returns the exec credential plugin configuration to embed in the remote secret. The
+// exec flags take precedence over the auth plugin configuration, which takes precedence over the exec configuration
+// of the context's user in the local kubeconfig.
+func execConfigForRemoteSecret(opt RemoteSecretOptions, context string, config *api.Config) (*api.ExecConfig, error) {
+	if opt.ExecCommand != "" || len(opt.ExecArgs) > 0 {
+		pluginConfig := make(map[string]string, len(opt.AuthPluginConfig)+1)
+		for k, v := range opt.AuthPluginConfig {
+			pluginConfig[k] = v
+		}
+		if opt.ExecCommand != "" {
+			pluginConfig[execPluginCommandKey] = opt.ExecCommand
+		}
+		delete(pluginConfig, execPluginArgsKey)
+		execConfig, err := execConfigFromAuthPluginConfig(pluginConfig)
+		if err != nil {
+			return nil, fmt.Errorf("--exec-command or the %q of --auth-plugin-config must be set with --exec-arg: %w",
+				execPluginCommandKey, err)
+		}
+		execConfig.Args = append([]string(nil), opt.ExecArgs...)
+		return execConfig, nil
+	}
+
+	if _, ok := opt.AuthPluginConfig[execPluginCommandKey]; ok {
+		return execConfigFromAuthPluginConfig(opt.AuthPluginConfig)
+	}
+
+	if configContext, ok := config.Contexts[context]; ok {
+		if authInfo, ok := config.AuthInfos[configContext.AuthInfo]; ok && authInfo.Exec != nil {
+			return authInfo.Exec.DeepCopy(), nil
+		}
+	}
+	return nil, fmt.Errorf("no exec credential plugin command set with --exec-command or --auth-plugin-config and "+
+		"the user of context %q has no exec configuration", context)
+}
+
+// execConfigFromAuthPluginConfig builds an exec credential plugin configuration from the generic auth plugin
+// configuration.
+func execConfigFromAuthPluginConfig(config map[string]string) (*api.ExecConfig, error) {
+	execConfig := &api.ExecConfig{
+		APIVersion: defaultExecPluginAPIVersion,
+	}
+	for k, v := range config {
+		switch k {
+		case execPluginCommandKey:
+			execConfig.Command = v
+		case execPluginArgsKey:
+			execConfig.Args = strings.Fields(v)
+		case execPluginAPIVersionKey:
+			execConfig.APIVersion = v
+		default:
+			execConfig.Env = append(execConfig.Env, api.ExecEnvVar{Name: k, Value: v})
+		}
+	}
+	if execConfig.Command == "" {
+		return nil, fmt.Errorf("--auth-plugin-config must set %q for the %v auth plugin", execPluginCommandKey, ExecAuthPluginName)
+	}
+	// map iteration order is random, keep the generated kubeconfig stable.
+	sort.Slice(execConfig.Env, func(i, j int) bool { return execConfig.Env[i].Name < execConfig.Env[j].Name })
+	return execConfig, nil
+}
+
 var (
 	errMissingRootCAKey = fmt.Errorf("no %q data found", v1.ServiceAccountRootCAKey)
 	errMissingTokenKey  = fmt.Errorf("no %q data found", v1.ServiceAccountTokenKey)
@@ -211,7 +345,36 @@ func createRemoteSecretFromTokenAndServer(tokenSecret *v1.Secret, clusterName, c
 	kubeconfig := createBearerTokenKubeconfig(caData, token, context, server)
 
 	// Encode the Kubeconfig in a secret that can be loaded by Istio to dynamically discover and access the remote cluster.
-	return createRemoteServiceAccountSecret(kubeconfig, clusterName, context)
+	out, err := createRemoteServiceAccountSecret(kubeconfig, clusterName, context)
+	if err != nil {
+		return nil, err
+	}
+
+	// Record when a time-limited token expires, so that the secret can be rotated in time.
+	if expiration, ok := tokenExpiration(token); ok {
+		out.Annotations[tokenExpirationAnnotationKey] = expiration.UTC().Format(time.RFC3339)
+	}
+	return out, nil
+}
+
+// tokenExpiration returns the expiration time of a bearer token, from the exp claim of the JWT. Legacy service account
+// tokens do not expire and have no exp claim.
+func tokenExpiration(token []byte) (time.Time, bool) {
+	parts := strings.Split(string(token), ".")
+	if len(parts) != 3 {
+		return time.Time{}, false
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(parts[1], "="))
+	if err != nil {
+		return time.Time{}, false
+	}
+	var claims struct {
+		Exp *int64 `json:"exp"`
+	}
+	if err := stdjson.Unmarshal(payload, &claims); err != nil || claims.Exp == nil {
+		return time.Time{}, false
+	}
+	return time.Unix(*claims.Exp, 0), true
 }
 
 func getServiceAccountSecretToken(kube kubernetes.Interface, saName, saNamespace string) (*v1.Secret, error) {
@@ -313,6 +476,11 @@ type RemoteSecretOptions struct {
 	// Authenticator plugin configuration
 	AuthPluginName   string
 	AuthPluginConfig map[string]string
+
+	// Exec credential plugin command and arguments. If neither these nor the auth plugin configuration specify
+	// a command, the exec configuration of the current user in the local kubeconfig is used.
+	ExecCommand string
+	ExecArgs    []string
 }
 
 func (o *RemoteSecretOptions) addFlags(flagset *pflag.FlagSet) {
@@ -331,14 +499,30 @@ func (o *RemoteSecretOptions) addFlags(flagset *pflag.FlagSet) {
 	flagset.StringVar(&o.AuthPluginName, "auth-plugin-name", o.AuthPluginName,
 		fmt.Sprintf("authenticator plug-in name. --auth-type=%v must be set with this option",
 			RemoteSecretAuthTypePlugin))
-	flagset.StringToString("auth-plugin-config", o.AuthPluginConfig,
+	flagset.StringToStringVar(&o.AuthPluginConfig, "auth-plugin-config", o.AuthPluginConfig,
 		fmt.Sprintf("authenticator plug-in configuration. --auth-type=%v must be set with this option",
 			RemoteSecretAuthTypePlugin))
+	flagset.StringVar(&o.ExecCommand, "exec-command", o.ExecCommand,
+		fmt.Sprintf("exec credential plug-in command. Implies --auth-type=%v --auth-plugin-name=%v",
+			RemoteSecretAuthTypePlugin, ExecAuthPluginName))
+	flagset.StringArrayVar(&o.ExecArgs, "exec-arg", o.ExecArgs,
+		"exec credential plug-in argument. May be repeated. Implies --exec-command")
 }
 
 func (o *RemoteSecretOptions) prepare(flags *pflag.FlagSet) error {
 	o.KubeOptions.prepare(flags)
 
+	// The exec flags imply the exec credential plugin.
+	if o.ExecCommand != "" || len(o.ExecArgs) > 0 {
+		if (flags.Changed("auth-type") && o.AuthType != RemoteSecretAuthTypePlugin) ||
+			(o.AuthPluginName != "" && o.AuthPluginName != ExecAuthPluginName) {
+			return fmt.Errorf("--exec-command and --exec-arg require --auth-type=%v --auth-plugin-name=%v",
+				RemoteSecretAuthTypePlugin, ExecAuthPluginName)
+		}
+		o.AuthType = RemoteSecretAuthTypePlugin
+		o.AuthPluginName = ExecAuthPluginName
+	}
+
 	if o.ClusterName != "" {
 		if !labels.IsDNS1123Label(o.ClusterName) {
 			return fmt.Errorf("%v is not a valid DNS 1123 label", o.ClusterName)
@@ -372,6 +556,21 @@ func createRemoteSecret(opt RemoteSecretOptions, client kubernetes.Interface, en
 	case RemoteSecretAuthTypeBearerToken:
 		remoteSecret, err = createRemoteSecretFromTokenAndServer(tokenSecret, opt.ClusterName, currentContext, server)
 	case RemoteSecretAuthTypePlugin:
+		var warning string
+		if warning, err = validateAuthPluginName(opt.AuthPluginName); err != nil {
+			return nil, err
+		}
+		if warning != "" {
+			env.Errorf("warning: %v\n", warning)
+		}
+		if opt.AuthPluginName == ExecAuthPluginName {
+			var execConfig *api.ExecConfig
+			if execConfig, err = execConfigForRemoteSecret(opt, currentContext, env.GetConfig()); err != nil {
+				return nil, err
+			}
+			remoteSecret, err = createRemoteSecretFromExecPlugin(tokenSecret, currentContext, server, opt.ClusterName, execConfig)
+			break
+		}
 		authProviderConfig := &api.AuthProviderConfig{
 			Name:   opt.AuthPluginName,
 			Config: opt.AuthPluginConfig,
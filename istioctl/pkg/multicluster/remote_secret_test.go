@@ -16,6 +16,7 @@ package multicluster
 
 import (
 	"bytes"
+	"encoding/base64"
 	"errors"
 	"fmt"
 	"strings"
@@ -499,6 +500,48 @@ users:
 	}
 }
 
+func TestCreateRemoteSecretTokenExpiration(t *testing.T) {
+	jwt := func(claims string) string {
+		enc := base64.RawURLEncoding
+		return enc.EncodeToString([]byte(`{"alg":"RS256","kid":""}`)) + "." + enc.EncodeToString([]byte(claims)) + ".c2lnbmF0dXJl"
+	}
+
+	cases := []struct {
+		name  string
+		token string
+		want  string
+	}{
+		{
+			name:  "bound token",
+			token: jwt(`{"aud":["api"],"exp":1606780800,"iat":1606777200,"iss":"https://kubernetes.default.svc","sub":"system:serviceaccount:istio-system:istio-reader-service-account"}`),
+			want:  "2020-12-01T00:00:00Z",
+		},
+		{
+			name:  "legacy token",
+			token: jwt(`{"iss":"kubernetes/serviceaccount","sub":"system:serviceaccount:istio-system:istio-reader-service-account"}`),
+		},
+		{
+			name:  "opaque token",
+			token: "token",
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got, err := createRemoteSecretFromTokenAndServer(makeSecret("", "caData", c.token), "cluster", "c0", "")
+			if err != nil {
+				t.Fatal(err)
+			}
+			expiration, ok := got.Annotations[tokenExpirationAnnotationKey]
+			if c.want == "" && ok {
+				t.Errorf("got token expiration annotation %q, want none", expiration)
+			}
+			if c.want != "" && expiration != c.want {
+				t.Errorf("got token expiration annotation %q, want %q", expiration, c.want)
+			}
+		})
+	}
+}
+
 func TestWriteEncodedSecret(t *testing.T) {
 	s := &v1.Secret{
 		ObjectMeta: metav1.ObjectMeta{
@@ -655,6 +698,318 @@ users:
 	}
 }
 
+func TestCreateRemoteSecretFromExecPlugin(t *testing.T) {
+	kubeconfig := `apiVersion: v1
+clusters:
+- cluster:
+    certificate-authority-data: Y2FEYXRh
+    server: ""
+  name: c0
+contexts:
+- context:
+    cluster: c0
+    user: c0
+  name: c0
+current-context: c0
+kind: Config
+preferences: {}
+users:
+- name: c0
+  user:
+    exec:
+      apiVersion: client.authentication.k8s.io/v1alpha1
+      args:
+      - token
+      - -i
+      - c0
+      command: aws-iam-authenticator
+      env:
+      - name: AWS_PROFILE
+        value: prod
+`
+	fakeClusterName := "fake-clusterName-0"
+
+	execConfig, err := execConfigFromAuthPluginConfig(map[string]string{
+		"command":     "aws-iam-authenticator",
+		"args":        "token -i c0",
+		"AWS_PROFILE": "prod",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		name        string
+		in          *v1.Secret
+		context     string
+		clusterName string
+		server      string
+		execConfig  *api.ExecConfig
+		want        *v1.Secret
+		wantErrStr  string
+	}{
+		{
+			name:        "error on missing caData",
+			in:          makeSecret("", "", "token"),
+			context:     "c0",
+			clusterName: fakeClusterName,
+			wantErrStr:  errMissingRootCAKey.Error(),
+		},
+		{
+			name:        "success",
+			in:          makeSecret("", "caData", "token"),
+			context:     "c0",
+			clusterName: fakeClusterName,
+			execConfig:  execConfig,
+			want: &v1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: remoteSecretNameFromClusterName(fakeClusterName),
+					Annotations: map[string]string{
+						"istio.io/clusterContext": "c0",
+					},
+					Labels: map[string]string{
+						secretcontroller.MultiClusterSecretLabel: "true",
+					},
+				},
+				Data: map[string][]byte{
+					fakeClusterName: []byte(kubeconfig),
+				},
+			},
+		},
+	}
+
+	for i := range cases {
+		c := &cases[i]
+		t.Run(fmt.Sprintf("[%v] %v", i, c.name), func(tt *testing.T) {
+			got, err := createRemoteSecretFromExecPlugin(c.in, c.context, c.server, c.clusterName, c.execConfig)
+			if c.wantErrStr != "" {
+				if err == nil {
+					tt.Fatalf("wanted error including %q but none", c.wantErrStr)
+				} else if !strings.Contains(err.Error(), c.wantErrStr) {
+					tt.Fatalf("wanted error including %q but %v", c.wantErrStr, err)
+				}
+			} else if c.wantErrStr == "" && err != nil {
+				tt.Fatalf("wanted non-error but got %q", err)
+			} else if diff := cmp.Diff(got, c.want); diff != "" {
+				tt.Fatalf(" got %v\nwant %v\ndiff %v", got, c.want, diff)
+			}
+		})
+	}
+}
+
+func TestCreateRemoteSecretAuthPluginValidation(t *testing.T) {
+	sa := makeServiceAccount("saSecret")
+	saSecret := makeSecret("saSecret", "caData", "token")
+	config := &api.Config{
+		CurrentContext: testContext,
+		Contexts: map[string]*api.Context{
+			testContext: {Cluster: "cluster"},
+		},
+		Clusters: map[string]*api.Cluster{
+			"cluster": {Server: "server"},
+		},
+	}
+
+	cases := []struct {
+		testName         string
+		pluginName       string
+		pluginConfig     map[string]string
+		wantWarning      bool
+		wantErrStr       string
+		wantOutputSubstr string
+	}{
+		{
+			testName:         "known auth provider",
+			pluginName:       "gcp",
+			wantOutputSubstr: "name: gcp",
+		},
+		{
+			testName:         "unknown auth provider",
+			pluginName:       "foobar",
+			wantWarning:      true,
+			wantOutputSubstr: "name: foobar",
+		},
+		{
+			testName:   "missing auth provider",
+			wantErrStr: errMissingAuthPluginName.Error(),
+		},
+		{
+			testName:         "exec plugin",
+			pluginName:       ExecAuthPluginName,
+			pluginConfig:     map[string]string{"command": "gke-gcloud-auth-plugin"},
+			wantOutputSubstr: "command: gke-gcloud-auth-plugin",
+		},
+		{
+			testName:   "exec plugin without command",
+			pluginName: ExecAuthPluginName,
+			wantErrStr: "no exec credential plugin command",
+		},
+	}
+
+	for i := range cases {
+		c := &cases[i]
+		t.Run(fmt.Sprintf("[%v] %v", i, c.testName), func(tt *testing.T) {
+			opts := RemoteSecretOptions{
+				ServiceAccountName: testServiceAccountName,
+				AuthType:           RemoteSecretAuthTypePlugin,
+				AuthPluginName:     c.pluginName,
+				AuthPluginConfig:   c.pluginConfig,
+				ClusterName:        "cluster-foo",
+				KubeOptions: KubeOptions{
+					Namespace:  testNamespace,
+					Context:    testContext,
+					Kubeconfig: testKubeconfig,
+				},
+			}
+			env := newFakeEnvironmentOrDie(t, config, kubeSystemNamespace, sa, saSecret)
+
+			got, err := CreateRemoteSecret(opts, env)
+			if c.wantErrStr != "" {
+				if err == nil || !strings.Contains(err.Error(), c.wantErrStr) {
+					tt.Fatalf("wanted error including %q but got %v", c.wantErrStr, err)
+				}
+				return
+			}
+			if err != nil {
+				tt.Fatalf("wanted non-error but got %q", err)
+			}
+			if !strings.Contains(got, c.wantOutputSubstr) {
+				tt.Errorf("output does not contain %q:\n%v", c.wantOutputSubstr, got)
+			}
+			gotWarning := strings.Contains(env.stderr.(*bytes.Buffer).String(), "warning")
+			if gotWarning != c.wantWarning {
+				tt.Errorf("got warning %v, want %v: %q", gotWarning, c.wantWarning, env.stderr.(*bytes.Buffer).String())
+			}
+		})
+	}
+}
+
+func TestExecConfigForRemoteSecret(t *testing.T) {
+	kubeconfigExec := &api.ExecConfig{
+		APIVersion: "client.authentication.k8s.io/v1beta1",
+		Command:    "gke-gcloud-auth-plugin",
+	}
+	config := &api.Config{
+		Contexts: map[string]*api.Context{
+			"exec-context":  {AuthInfo: "exec-user"},
+			"token-context": {AuthInfo: "token-user"},
+		},
+		AuthInfos: map[string]*api.AuthInfo{
+			"exec-user":  {Exec: kubeconfigExec},
+			"token-user": {Token: "token"},
+		},
+	}
+
+	cases := []struct {
+		name       string
+		opt        RemoteSecretOptions
+		context    string
+		want       *api.ExecConfig
+		wantErrStr string
+	}{
+		{
+			name: "from flags",
+			opt: RemoteSecretOptions{
+				ExecCommand:      "aws-iam-authenticator",
+				ExecArgs:         []string{"token", "-i", "c0"},
+				AuthPluginConfig: map[string]string{"AWS_PROFILE": "prod"},
+			},
+			context: "exec-context",
+			want: &api.ExecConfig{
+				APIVersion: defaultExecPluginAPIVersion,
+				Command:    "aws-iam-authenticator",
+				Args:       []string{"token", "-i", "c0"},
+				Env:        []api.ExecEnvVar{{Name: "AWS_PROFILE", Value: "prod"}},
+			},
+		},
+		{
+			name: "args without command",
+			opt: RemoteSecretOptions{
+				ExecArgs: []string{"token"},
+			},
+			context:    "exec-context",
+			wantErrStr: "--exec-command or the \"command\" of --auth-plugin-config must be set",
+		},
+		{
+			name: "args with the command from auth plugin config",
+			opt: RemoteSecretOptions{
+				ExecArgs:         []string{"get-token", "--login", "azurecli"},
+				AuthPluginConfig: map[string]string{"command": "kubelogin", "args": "ignored"},
+			},
+			context: "exec-context",
+			want: &api.ExecConfig{
+				APIVersion: defaultExecPluginAPIVersion,
+				Command:    "kubelogin",
+				Args:       []string{"get-token", "--login", "azurecli"},
+			},
+		},
+		{
+			name: "from auth plugin config",
+			opt: RemoteSecretOptions{
+				AuthPluginConfig: map[string]string{"command": "kubelogin", "args": "get-token"},
+			},
+			context: "exec-context",
+			want: &api.ExecConfig{
+				APIVersion: defaultExecPluginAPIVersion,
+				Command:    "kubelogin",
+				Args:       []string{"get-token"},
+			},
+		},
+		{
+			name:    "from kubeconfig",
+			context: "exec-context",
+			want:    kubeconfigExec,
+		},
+		{
+			name:       "kubeconfig user without exec",
+			context:    "token-context",
+			wantErrStr: `the user of context "token-context" has no exec configuration`,
+		},
+	}
+
+	for i := range cases {
+		c := &cases[i]
+		t.Run(fmt.Sprintf("[%v] %v", i, c.name), func(tt *testing.T) {
+			got, err := execConfigForRemoteSecret(c.opt, c.context, config)
+			if c.wantErrStr != "" {
+				if err == nil || !strings.Contains(err.Error(), c.wantErrStr) {
+					tt.Fatalf("wanted error including %q but got %v", c.wantErrStr, err)
+				}
+			} else if err != nil {
+				tt.Fatalf("wanted non-error but got %q", err)
+			} else if diff := cmp.Diff(got, c.want); diff != "" {
+				tt.Fatalf(" got %v\nwant %v\ndiff %v", got, c.want, diff)
+			}
+		})
+	}
+}
+
+func TestRemoteSecretOptionsExecFlags(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	o := RemoteSecretOptions{AuthType: RemoteSecretAuthTypeBearerToken}
+	flags := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	o.addFlags(flags)
+	g.Expect(flags.Parse([]string{
+		"--exec-command", "aws-iam-authenticator",
+		"--exec-arg", "token",
+		"--exec-arg", "-i",
+	})).Should(Succeed())
+	g.Expect(o.prepare(flags)).Should(Succeed())
+	g.Expect(o.AuthType).Should(Equal(RemoteSecretAuthTypePlugin))
+	g.Expect(o.AuthPluginName).Should(Equal(ExecAuthPluginName))
+	g.Expect(o.ExecArgs).Should(Equal([]string{"token", "-i"}))
+
+	o = RemoteSecretOptions{AuthType: RemoteSecretAuthTypeBearerToken}
+	flags = pflag.NewFlagSet("test", pflag.ContinueOnError)
+	o.addFlags(flags)
+	g.Expect(flags.Parse([]string{
+		"--exec-command", "aws-iam-authenticator",
+		"--auth-type", "bearer-token",
+	})).Should(Succeed())
+	g.Expect(o.prepare(flags)).Should(Not(Succeed()))
+}
+
 func TestRemoteSecretOptions(t *testing.T) {
 	g := NewGomegaWithT(t)
 
@@ -19,6 +19,10 @@ import (
 	"os"
 	"time"
 
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+
+	iop "istio.io/istio/operator/pkg/apis/istio/v1alpha1"
 	"istio.io/istio/operator/pkg/kubectlcmd"
 	"istio.io/istio/operator/pkg/manifest"
 	"istio.io/istio/operator/pkg/name"
@@ -119,7 +123,7 @@ func ApplyManifests(setOverlay []string, inFilenames []string, force bool, dryRu
 	if err != nil {
 		return err
 	}
-	manifests, _, err := GenManifests(inFilenames, ysf, force, kubeconfig, l)
+	manifests, iops, err := GenManifests(inFilenames, ysf, force, kubeconfig, l)
 	if err != nil {
 		return fmt.Errorf("failed to generate manifest: %v", err)
 	}
@@ -140,6 +144,12 @@ func ApplyManifests(setOverlay []string, inFilenames []string, force bool, dryRu
 	if err != nil {
 		return fmt.Errorf("failed to apply manifest with kubectl client: %v", err)
 	}
+	if !dryRun {
+		status := manifest.NewInstallStatus(out, version.OperatorBinaryVersion.String(), time.Now())
+		if err := recordInstallStatus(kubeconfig, iop.Namespace(iops), status); err != nil {
+			l.logAndErrorf("Failed to record the install status: %v", err)
+		}
+	}
 	gotError := false
 
 	for cn := range manifests {
@@ -164,3 +174,13 @@ func ApplyManifests(setOverlay []string, inFilenames []string, force bool, dryRu
 	l.logAndPrint("\n\n✔ Installation complete\n")
 	return nil
 }
+
+// recordInstallStatus writes status to the install status ConfigMap in the given namespace, so that later commands
+// can tell which components were installed without rendering the manifests again.
+func recordInstallStatus(restConfig *rest.Config, namespace string, status manifest.InstallStatus) error {
+	cs, err := kubernetes.NewForConfig(restConfig)
+	if err != nil {
+		return fmt.Errorf("k8s client error: %s", err)
+	}
+	return manifest.WriteInstallStatus(cs, namespace, status)
+}
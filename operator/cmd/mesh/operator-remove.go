@@ -107,7 +107,13 @@ func deleteManifest(manifestStr, componentName string, opts *kubectlcmd.Options,
 		l.logAndPrint("Parse error: ", err, "\n")
 		return false
 	}
-	stdout, stderr, err := kubectlcmd.New().Delete(manifestStr, opts)
+	// Delete in reverse apply order so that e.g. namespaces and CRDs are removed after the resources that use them.
+	mstr, err := object.SortByDeleteOrder(objs).YAMLManifest()
+	if err != nil {
+		l.logAndPrint("Manifest render error: ", err, "\n")
+		return false
+	}
+	stdout, stderr, err := kubectlcmd.New().Delete(mstr, opts)
 
 	success := true
 	if err != nil {
@@ -127,5 +133,12 @@ func deleteManifest(manifestStr, componentName string, opts *kubectlcmd.Options,
 		l.logAndPrint(stdout, "\n")
 		success = false
 	}
+	if success {
+		// The resources are already being deleted, so a slow deletion, e.g. of a namespace with finalizers, does not
+		// fail the removal.
+		if err := manifest.WaitForDeletion(objs, opts); err != nil {
+			l.logAndPrint("Warning: ", err, "\n")
+		}
+	}
 	return success
 }
@@ -17,10 +17,13 @@ package mesh
 import (
 	"fmt"
 	"os"
+	"sort"
+	"strings"
 	"time"
 
 	goversion "github.com/hashicorp/go-version"
 	"github.com/spf13/cobra"
+	"k8s.io/client-go/kubernetes"
 
 	iop "istio.io/istio/operator/pkg/apis/istio/v1alpha1"
 	"istio.io/istio/operator/pkg/compare"
@@ -145,6 +148,13 @@ func upgrade(rootArgs *rootArgs, args *upgradeArgs, l *Logger) (err error) {
 		return fmt.Errorf("failed to read the current Istio version, error: %v", err)
 	}
 
+	// Compare with the components recorded by the last istioctl install, if any
+	if status, err := readInstallStatus(args.kubeConfigPath, args.context, istioNamespace); err != nil {
+		log.Infof("no recorded install status: %v", err)
+	} else {
+		checkRecordedInstallStatus(status, currentVersion, l)
+	}
+
 	// Check if the upgrade currentVersion -> targetVersion is supported
 	err = checkSupportedVersions(currentVersion, targetVersion, args.versionsURI)
 	if err != nil && !args.force {
@@ -244,6 +254,39 @@ func waitForConfirmation(skipConfirmation bool, l *Logger) {
 	}
 }
 
+// readInstallStatus reads the install status recorded by istioctl in the Istio namespace.
+func readInstallStatus(kubeConfigPath, context, istioNamespace string) (manifest.InstallStatus, error) {
+	restConfig, err := manifest.InitK8SRestClient(kubeConfigPath, context)
+	if err != nil {
+		return nil, err
+	}
+	cs, err := kubernetes.NewForConfig(restConfig)
+	if err != nil {
+		return nil, fmt.Errorf("k8s client error: %s", err)
+	}
+	return manifest.ReadInstallStatus(cs, istioNamespace)
+}
+
+// checkRecordedInstallStatus warns about components whose recorded install version differs from the version of the
+// running control plane, or whose last install failed.
+func checkRecordedInstallStatus(status manifest.InstallStatus, currentVersion string, l *Logger) {
+	var mismatched []string
+	for c, cs := range status {
+		switch {
+		case cs.Error != "":
+			mismatched = append(mismatched, fmt.Sprintf("%s (install failed at %s: %s)", c, cs.Version, cs.Error))
+		case cs.Version != currentVersion:
+			mismatched = append(mismatched, fmt.Sprintf("%s (installed at %s)", c, cs.Version))
+		}
+	}
+	if len(mismatched) == 0 {
+		return
+	}
+	sort.Strings(mismatched)
+	l.logAndPrintf("Warning: the recorded install status of these components does not match the running version %s:\n  %s\n",
+		currentVersion, strings.Join(mismatched, "\n  "))
+}
+
 // checkSupportedVersions checks if the upgrade cur -> tar is supported by the tool
 func checkSupportedVersions(cur, tar, versionsURI string) error {
 	tarGoVersion, err := goversion.NewVersion(tar)
@@ -26,6 +26,7 @@ import (
 	"k8s.io/apimachinery/pkg/types"
 
 	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/discovery"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/event"
@@ -54,12 +55,29 @@ const (
 // Add creates a new IstioOperator Controller and adds it to the Manager. The Manager will set fields on the Controller
 // and Start it when the Manager is Started.
 func Add(mgr manager.Manager) error {
-	return add(mgr, newReconciler(mgr))
+	r := newReconciler(mgr)
+	// Cancel the reconciles in progress when the manager stops, e.g. on SIGTERM, so that they stop applying components.
+	ctx, cancel := context.WithCancel(context.Background())
+	r.ctx = ctx
+	if err := mgr.Add(manager.RunnableFunc(func(stop <-chan struct{}) error {
+		<-stop
+		cancel()
+		return nil
+	})); err != nil {
+		cancel()
+		return err
+	}
+	return add(mgr, r)
 }
 
 // newReconciler returns a new reconcile.Reconciler
-func newReconciler(mgr manager.Manager) reconcile.Reconciler {
+func newReconciler(mgr manager.Manager) *ReconcileIstioOperator {
 	factory := &helmreconciler.Factory{CustomizerFactory: &IstioRenderingCustomizerFactory{}}
+	if d, err := discovery.NewDiscoveryClientForConfig(mgr.GetConfig()); err != nil {
+		log.Warnf("failed to create discovery client, pruning only the static resource types: %v", err)
+	} else {
+		factory.DiscoveryClient = d
+	}
 	return &ReconcileIstioOperator{client: mgr.GetClient(), scheme: mgr.GetScheme(), factory: factory}
 }
 
@@ -95,6 +113,8 @@ type ReconcileIstioOperator struct {
 	client  client.Client
 	scheme  *runtime.Scheme
 	factory *helmreconciler.Factory
+	// ctx is cancelled when the manager stops. Reconciles are not cancelled if it is nil.
+	ctx context.Context
 }
 
 // Reconcile reads that state of the cluster for a IstioOperator object and makes changes based on the state read
@@ -202,7 +222,11 @@ func (r *ReconcileIstioOperator) Reconcile(request reconcile.Request) (reconcile
 	}
 	reconciler, err := r.getOrCreateReconciler(&iopMerged)
 	if err == nil {
-		err = reconciler.Reconcile()
+		ctx := r.ctx
+		if ctx == nil {
+			ctx = context.Background()
+		}
+		err = reconciler.Reconcile(ctx)
 		if err != nil {
 			log.Errorf("reconciling err: %s", err)
 		}
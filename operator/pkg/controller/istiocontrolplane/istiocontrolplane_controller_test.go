@@ -208,3 +208,65 @@ func checkIOPStatus(cl client.Client, key client.ObjectKey, profile string) (boo
 	}
 	return true, nil
 }
+
+// cancellingClient cancels a reconcile once the first object is created.
+type cancellingClient struct {
+	client.Client
+	cancel context.CancelFunc
+}
+
+func (c *cancellingClient) Create(ctx context.Context, obj runtime.Object, opts ...client.CreateOption) error {
+	c.cancel()
+	return c.Client.Create(ctx, obj, opts...)
+}
+
+func TestIOPController_CancelReconcile(t *testing.T) {
+	iopinstance := &iop.IstioOperator{
+		Kind:       "IstioOperator",
+		ApiVersion: "install.istio.io/v1alpha1",
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "cancelled-istiocontrolplane",
+			Namespace: "istio-system",
+		},
+		Spec: &v1alpha1.IstioOperatorSpec{
+			Profile: "default",
+			MeshConfig: &mesh.MeshConfig{
+				RootNamespace: "istio-system",
+			},
+		},
+	}
+	s := scheme.Scheme
+	s.AddKnownTypes(iop.SchemeGroupVersion, iopinstance)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	cl := &cancellingClient{Client: fake.NewFakeClientWithScheme(s, iopinstance), cancel: cancel}
+
+	iopMerged := *iopinstance
+	var err error
+	iopMerged.Spec, err = helmreconciler.MergeIOPSWithProfile(iopinstance.Spec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	factory := &helmreconciler.Factory{CustomizerFactory: &IstioRenderingCustomizerFactory{}}
+	reconciler, err := factory.New(&iopMerged, cl)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Every component depends on the base component, so cancelling while it is applied leaves all others pending.
+	err = reconciler.Reconcile(ctx)
+	cancelled, ok := err.(*helmreconciler.ReconcileCancelledError)
+	if !ok {
+		t.Fatalf("got error %v, want a ReconcileCancelledError", err)
+	}
+	if len(cancelled.Completed) != 1 || cancelled.Completed[0] != name.IstioBaseComponentName {
+		t.Errorf("got completed components %v, want [%s]", cancelled.Completed, name.IstioBaseComponentName)
+	}
+	if len(cancelled.Partial) != 0 {
+		t.Errorf("got partially applied components %v, want none", cancelled.Partial)
+	}
+	wantPending := []name.ComponentName{name.AddonComponentName, name.IngressComponentName, name.PilotComponentName}
+	if fmt.Sprint(cancelled.Pending) != fmt.Sprint(wantPending) {
+		t.Errorf("got pending components %v, want %v", cancelled.Pending, wantPending)
+	}
+}
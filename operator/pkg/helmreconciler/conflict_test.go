@@ -0,0 +1,104 @@
+// Copyright 2020 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package helmreconciler
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"istio.io/istio/operator/pkg/object"
+)
+
+// conflictClient rejects the first conflicts updates with a Conflict error, as the API server does when the object
+// was changed since it was read.
+type conflictClient struct {
+	client.Client
+	conflicts int
+	updates   int
+}
+
+func (c *conflictClient) Update(ctx context.Context, obj runtime.Object, opts ...client.UpdateOption) error {
+	c.updates++
+	if c.updates <= c.conflicts {
+		return apierrors.NewConflict(schema.GroupResource{Resource: "services"}, "istiod", errors.New("the object has been modified"))
+	}
+	return c.Client.Update(ctx, obj, opts...)
+}
+
+func TestProcessObjectRetriesOnConflict(t *testing.T) {
+	defer func(b wait.Backoff) { updateConflictBackoff = b }(updateConflictBackoff)
+	updateConflictBackoff = wait.Backoff{Steps: 3, Duration: time.Millisecond}
+
+	objs, err := object.ParseK8sObjectsFromYAMLManifest(immutableTestService)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		desc      string
+		conflicts int
+		wantErr   bool
+	}{
+		{"NoConflict", 0, false},
+		{"ConflictOnFirstUpdate", 1, false},
+		{"PersistentConflict", 3, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			live := &v1.Service{
+				ObjectMeta: metav1.ObjectMeta{Name: "istiod", Namespace: "istio-system"},
+				Spec:       v1.ServiceSpec{ClusterIP: "10.0.0.10"},
+			}
+			cl := &conflictClient{Client: fake.NewFakeClientWithScheme(scheme.Scheme, live), conflicts: tt.conflicts}
+			h := &HelmReconciler{
+				client:     cl,
+				customizer: &SimpleRenderingCustomizer{ListenerValue: &CompositeRenderingListener{}},
+			}
+
+			err := h.ProcessObject("Pilot", objs[0].UnstructuredObject())
+			if tt.wantErr {
+				if !apierrors.IsConflict(err) {
+					t.Fatalf("got error %v, want a conflict error", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if cl.updates != tt.conflicts+1 {
+				t.Errorf("got %d updates, want %d", cl.updates, tt.conflicts+1)
+			}
+			got := &v1.Service{}
+			if err := cl.Get(context.TODO(), client.ObjectKey{Namespace: "istio-system", Name: "istiod"}, got); err != nil {
+				t.Fatal(err)
+			}
+			if got.Spec.Selector["app"] != "istiod" {
+				t.Errorf("got selector %v, want the updated selector", got.Spec.Selector)
+			}
+		})
+	}
+}
@@ -0,0 +1,57 @@
+// Copyright 2020 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package helmreconciler
+
+import (
+	"reflect"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// immutableFields are the fields of each kind that the API server refuses to change once an object exists, or that
+// are assigned by the API server when the object is created.
+var immutableFields = map[schema.GroupKind][][]string{
+	{Group: "", Kind: "Service"}:                   {{"spec", "clusterIP"}},
+	{Group: "batch", Kind: "Job"}:                  {{"spec", "selector"}, {"spec", "template"}},
+	{Group: "apps", Kind: "Deployment"}:            {{"spec", "selector"}},
+	{Group: "apps", Kind: "DaemonSet"}:             {{"spec", "selector"}},
+	{Group: "apps", Kind: "StatefulSet"}:           {{"spec", "selector"}, {"spec", "serviceName"}, {"spec", "volumeClaimTemplates"}},
+	{Group: "policy", Kind: "PodDisruptionBudget"}: {{"spec", "selector"}},
+}
+
+// preserveImmutableFields sets each immutable field of desired that differs from live to its live value, so that
+// updating live with desired does not fail. Fields missing from either object are left alone, since a merge keeps
+// the live value for them. It returns the paths of the fields that were changed in desired.
+func preserveImmutableFields(live, desired *unstructured.Unstructured) []string {
+	var preserved []string
+	for _, path := range immutableFields[desired.GroupVersionKind().GroupKind()] {
+		liveValue, found, err := unstructured.NestedFieldNoCopy(live.Object, path...)
+		if err != nil || !found {
+			continue
+		}
+		desiredValue, found, err := unstructured.NestedFieldNoCopy(desired.Object, path...)
+		if err != nil || !found || reflect.DeepEqual(liveValue, desiredValue) {
+			continue
+		}
+		if err := unstructured.SetNestedField(desired.Object, runtime.DeepCopyJSONValue(liveValue), path...); err != nil {
+			continue
+		}
+		preserved = append(preserved, strings.Join(path, "."))
+	}
+	return preserved
+}
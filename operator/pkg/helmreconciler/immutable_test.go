@@ -0,0 +1,104 @@
+// Copyright 2020 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package helmreconciler
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"istio.io/istio/operator/pkg/object"
+)
+
+// clusterIPClient rejects updates changing the cluster IP of a Service, like the API server does.
+type clusterIPClient struct {
+	client.Client
+}
+
+func (c *clusterIPClient) Update(ctx context.Context, obj runtime.Object, opts ...client.UpdateOption) error {
+	u := obj.(*unstructured.Unstructured)
+	live := &v1.Service{}
+	if err := c.Get(ctx, client.ObjectKey{Namespace: u.GetNamespace(), Name: u.GetName()}, live); err != nil {
+		return err
+	}
+	if clusterIP, _, _ := unstructured.NestedString(u.Object, "spec", "clusterIP"); clusterIP != live.Spec.ClusterIP {
+		return fmt.Errorf("spec.clusterIP: Invalid value: %q: field is immutable", clusterIP)
+	}
+	return c.Client.Update(ctx, obj, opts...)
+}
+
+const immutableTestService = `
+apiVersion: v1
+kind: Service
+metadata:
+  name: istiod
+  namespace: istio-system
+spec:
+  clusterIP: ""
+  ports:
+  - name: grpc-xds
+    port: 15010
+  selector:
+    app: istiod
+`
+
+func TestProcessObjectKeepsServerAssignedClusterIP(t *testing.T) {
+	live := &v1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: "istiod", Namespace: "istio-system"},
+		Spec: v1.ServiceSpec{
+			ClusterIP: "10.0.0.10",
+			Ports:     []v1.ServicePort{{Name: "grpc-xds", Port: 15010}},
+		},
+	}
+	cl := &clusterIPClient{Client: fake.NewFakeClientWithScheme(scheme.Scheme, live)}
+	h := &HelmReconciler{
+		client:     cl,
+		customizer: &SimpleRenderingCustomizer{ListenerValue: &CompositeRenderingListener{}},
+	}
+
+	objs, err := object.ParseK8sObjectsFromYAMLManifest(immutableTestService)
+	if err != nil {
+		t.Fatal(err)
+	}
+	desired := objs[0].UnstructuredObject()
+	// Re-applying the same manifest must succeed every time.
+	for i := 0; i < 2; i++ {
+		if err := h.ProcessObject("Pilot", desired); err != nil {
+			t.Fatalf("apply %d: %v", i, err)
+		}
+	}
+
+	got := &v1.Service{}
+	if err := cl.Get(context.TODO(), client.ObjectKey{Namespace: "istio-system", Name: "istiod"}, got); err != nil {
+		t.Fatal(err)
+	}
+	if got.Spec.ClusterIP != "10.0.0.10" {
+		t.Errorf("got cluster IP %q, want 10.0.0.10", got.Spec.ClusterIP)
+	}
+	if got.Spec.Selector["app"] != "istiod" {
+		t.Errorf("got selector %v, want the updated selector", got.Spec.Selector)
+	}
+	if clusterIP, _, _ := unstructured.NestedString(desired.Object, "spec", "clusterIP"); clusterIP != "" {
+		t.Errorf("the desired object was modified, got cluster IP %q", clusterIP)
+	}
+}
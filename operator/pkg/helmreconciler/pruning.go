@@ -32,6 +32,9 @@ import (
 func (h *HelmReconciler) Prune(excluded map[string]bool, all bool) error {
 	allErrors := []error{}
 	namespacedResources, clusterResources := h.customizer.PruningDetails().GetResourceTypes()
+	if h.discovery != nil {
+		namespacedResources, clusterResources = DiscoverResourceTypes(h.discovery, namespacedResources, clusterResources)
+	}
 	targetNamespace := h.customizer.Input().GetTargetNamespace()
 	err := h.PruneUnlistedResources(append(namespacedResources, clusterResources...), excluded, all, targetNamespace)
 	if all {
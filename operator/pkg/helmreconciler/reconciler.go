@@ -15,10 +15,15 @@
 package helmreconciler
 
 import (
+	"context"
+	"fmt"
+	"sort"
 	"sync"
 
 	"k8s.io/apimachinery/pkg/runtime"
 	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/client-go/discovery"
+	"k8s.io/helm/pkg/manifest"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	"istio.io/api/operator/v1alpha1"
@@ -33,6 +38,7 @@ import (
 // or deletes all resources associated with a specific instance of a custom resource.
 type HelmReconciler struct {
 	client             client.Client
+	discovery          discovery.DiscoveryInterface
 	customizer         RenderingCustomizer
 	instance           *iop.IstioOperator
 	needUpdateAndPrune bool
@@ -42,6 +48,9 @@ type HelmReconciler struct {
 type Factory struct {
 	// CustomizerFactory is a factory for creating the Customizer object for the HelmReconciler.
 	CustomizerFactory RenderingCustomizerFactory
+	// DiscoveryClient is used to find the Istio resource types served by the cluster. If nil, only the static
+	// resource types of the pruning details are used.
+	DiscoveryClient discovery.DiscoveryInterface
 }
 
 // New Returns a new HelmReconciler for the custom resource.
@@ -57,7 +66,8 @@ func (f *Factory) New(instance *iop.IstioOperator, client client.Client) (*HelmR
 	if err != nil {
 		return nil, err
 	}
-	reconciler := &HelmReconciler{client: client, customizer: wrappedcustomizer, instance: instance, needUpdateAndPrune: true}
+	reconciler := &HelmReconciler{client: client, discovery: f.DiscoveryClient, customizer: wrappedcustomizer, instance: instance,
+		needUpdateAndPrune: true}
 	wrappedcustomizer.RegisterReconciler(reconciler)
 	return reconciler, nil
 }
@@ -86,8 +96,32 @@ func wrapCustomizer(instance runtime.Object, delegate RenderingCustomizer) (*Sim
 	}, nil
 }
 
-// Reconcile the resources associated with the custom resource instance.
-func (h *HelmReconciler) Reconcile() error {
+// ReconcileCancelledError is returned by Reconcile when its context is cancelled. Independent components are processed
+// concurrently, but each component is either completed, partially applied (processed with errors) or pending (not
+// started), since the components already in progress are allowed to finish. Nothing is rolled back, and resources are
+// not pruned after a cancelled reconcile.
+type ReconcileCancelledError struct {
+	// Err is the error of the cancelled context.
+	Err error
+	// Completed are the components that were fully applied.
+	Completed []name.ComponentName
+	// Partial are the components that were processed with errors, so some of their resources may not be applied.
+	Partial []name.ComponentName
+	// Pending are the components that were not started.
+	Pending []name.ComponentName
+}
+
+func (e *ReconcileCancelledError) Error() string {
+	return fmt.Sprintf("reconcile stopped: %v; completed components: %v, partially applied components: %v, pending components: %v",
+		e.Err, e.Completed, e.Partial, e.Pending)
+}
+
+// errComponentPending is the status error of components not started before a reconcile was cancelled.
+const errComponentPending = "reconcile was cancelled before the component was applied"
+
+// Reconcile the resources associated with the custom resource instance. If ctx is cancelled, no more components are
+// started and a *ReconcileCancelledError is returned once the components already in progress finish.
+func (h *HelmReconciler) Reconcile(ctx context.Context) error {
 	// any processing required before processing the charts
 	err := h.customizer.Listener().BeginReconcile(h.instance)
 	if err != nil {
@@ -101,7 +135,20 @@ func (h *HelmReconciler) Reconcile() error {
 		return err
 	}
 
-	status := h.processRecursive(manifestMap)
+	// Report Istio resources rejected by validation before applying anything.
+	if errs := h.validateIstioResources(manifestMap); len(errs) != 0 {
+		return h.endReconcileWithValidationErrors(errs)
+	}
+
+	status := h.processRecursive(ctx, manifestMap)
+
+	// Leave everything in place after a cancelled reconcile, so that the reported state stays accurate.
+	if ctx.Err() != nil {
+		if err := h.customizer.Listener().EndReconcile(h.instance, status); err != nil {
+			log.Errorf("error calling listener: %s", err)
+		}
+		return newReconcileCancelledError(ctx.Err(), status)
+	}
 
 	// Delete any resources not in the manifest but managed by operator.
 	var errs util.Errors
@@ -113,9 +160,58 @@ func (h *HelmReconciler) Reconcile() error {
 	return errs.ToError()
 }
 
+// endReconcileWithValidationErrors ends a reconcile in which nothing was applied because the resources of some
+// components failed validation, and returns the validation errors. Those components are reported in the ERROR state.
+func (h *HelmReconciler) endReconcileWithValidationErrors(errs map[string]error) error {
+	status := &v1alpha1.InstallStatus{
+		Status:          v1alpha1.InstallStatus_ERROR,
+		ComponentStatus: make(map[string]*v1alpha1.InstallStatus_VersionStatus),
+	}
+	components := make([]string, 0, len(errs))
+	for c, err := range errs {
+		components = append(components, c)
+		status.ComponentStatus[c] = &v1alpha1.InstallStatus_VersionStatus{
+			Status: v1alpha1.InstallStatus_ERROR,
+			Error:  err.Error(),
+		}
+	}
+	sort.Strings(components)
+
+	var ret util.Errors
+	for _, c := range components {
+		ret = util.AppendErr(ret, errs[c])
+	}
+	if err := h.customizer.Listener().EndReconcile(h.instance, status); err != nil {
+		log.Errorf("error calling listener: %s", err)
+	}
+	return ret.ToError()
+}
+
+// newReconcileCancelledError returns the error reporting the state of each component in status after a cancelled
+// reconcile.
+func newReconcileCancelledError(err error, status *v1alpha1.InstallStatus) *ReconcileCancelledError {
+	ret := &ReconcileCancelledError{Err: err}
+	for c, cs := range status.ComponentStatus {
+		cn := name.ComponentName(c)
+		switch {
+		case cs.Status == v1alpha1.InstallStatus_HEALTHY:
+			ret.Completed = append(ret.Completed, cn)
+		case cs.Error == errComponentPending:
+			ret.Pending = append(ret.Pending, cn)
+		default:
+			ret.Partial = append(ret.Partial, cn)
+		}
+	}
+	for _, l := range [][]name.ComponentName{ret.Completed, ret.Partial, ret.Pending} {
+		sort.Slice(l, func(i, j int) bool { return l[i] < l[j] })
+	}
+	return ret
+}
+
 // processRecursive processes the given manifests in an order of dependencies defined in h. Dependencies are a tree,
-// where a child must wait for the parent to complete before starting.
-func (h *HelmReconciler) processRecursive(manifests ChartManifestsMap) *v1alpha1.InstallStatus {
+// where a child must wait for the parent to complete before starting. Components not started when ctx is cancelled
+// are left pending.
+func (h *HelmReconciler) processRecursive(ctx context.Context, manifests ChartManifestsMap) *v1alpha1.InstallStatus {
 	deps, dch := h.customizer.Input().GetProcessingOrder(manifests)
 	componentStatus := make(map[string]*v1alpha1.InstallStatus_VersionStatus)
 
@@ -132,8 +228,22 @@ func (h *HelmReconciler) processRecursive(manifests ChartManifestsMap) *v1alpha1
 			cn := name.ComponentName(c)
 			if s := dch[cn]; s != nil {
 				log.Infof("%s is waiting on dependency...", c)
-				<-s
-				log.Infof("Dependency for %s has completed, proceeding.", c)
+				select {
+				case <-s:
+					log.Infof("Dependency for %s has completed, proceeding.", c)
+				case <-ctx.Done():
+				}
+			}
+
+			if ctx.Err() != nil && hasObjects(m) {
+				log.Infof("Reconcile was cancelled, not processing %s.", c)
+				mu.Lock()
+				componentStatus[c] = &v1alpha1.InstallStatus_VersionStatus{
+					Status: v1alpha1.InstallStatus_RECONCILING,
+					Error:  errComponentPending,
+				}
+				mu.Unlock()
+				return
 			}
 
 			// Set status when reconciling starts
@@ -242,6 +352,17 @@ func (h *HelmReconciler) Delete() error {
 	return err
 }
 
+// hasObjects reports whether the manifests of a component contain any objects to process.
+func hasObjects(m []manifest.Manifest) bool {
+	for _, mm := range m {
+		objs, err := object.ParseK8sObjectsFromYAMLManifest(mm.Content)
+		if err != nil || len(objs) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
 // allObjectHashes returns a map with object hashes of all the objects contained in cmm as the keys.
 func allObjectHashes(cmm ChartManifestsMap) map[string]bool {
 	ret := make(map[string]bool)
@@ -0,0 +1,54 @@
+// Copyright 2020 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package helmreconciler
+
+import (
+	"testing"
+
+	"k8s.io/helm/pkg/manifest"
+)
+
+func TestHasObjects(t *testing.T) {
+	tests := []struct {
+		desc string
+		m    []manifest.Manifest
+		want bool
+	}{
+		{
+			desc: "NoManifests",
+		},
+		{
+			desc: "EmptyManifests",
+			m:    []manifest.Manifest{{Name: "a"}, {Name: "b", Content: "---\n"}},
+		},
+		{
+			desc: "ObjectsInFirstManifest",
+			m:    []manifest.Manifest{{Name: "a", Content: immutableTestService}, {Name: "b"}},
+			want: true,
+		},
+		{
+			desc: "ObjectsInLaterManifest",
+			m:    []manifest.Manifest{{Name: "a"}, {Name: "b", Content: immutableTestService}},
+			want: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			if got := hasObjects(tt.m); got != tt.want {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
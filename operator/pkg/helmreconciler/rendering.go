@@ -30,6 +30,7 @@ import (
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/client-go/util/retry"
 	"k8s.io/helm/pkg/manifest"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
@@ -297,10 +298,49 @@ func (h *HelmReconciler) ProcessObject(chartName string, obj *unstructured.Unstr
 		return h.client.Create(context.TODO(), mutatedObj)
 	} else if err == nil {
 		log.Infof("updating resource: %s", objectKey)
-		if err := applyOverlay(receiver, mutatedObj); err != nil {
+		return h.updateObject(objectKey, receiver, mutatedObj)
+	}
+	return err
+}
+
+// updateConflictBackoff bounds the retries of an update rejected because the object changed since it was read.
+var updateConflictBackoff = retry.DefaultBackoff
+
+// updateObject updates live, the current version of the object with the given key, with desired. If the update
+// conflicts with a concurrent change, it is retried with backoff against the latest version of the object.
+func (h *HelmReconciler) updateObject(objectKey client.ObjectKey, live *unstructured.Unstructured, desired runtime.Object) error {
+	if u, ok := desired.(*unstructured.Unstructured); ok {
+		desired = u.DeepCopy()
+	}
+	attempt := 0
+	err := retry.RetryOnConflict(updateConflictBackoff, func() error {
+		if attempt > 0 {
+			log.Infof("conflict updating resource %s, retrying with its latest version", objectKey)
+			latest := &unstructured.Unstructured{}
+			latest.SetGroupVersionKind(live.GroupVersionKind())
+			if err := h.client.Get(context.TODO(), objectKey, latest); err != nil {
+				return err
+			}
+			live = latest
+		}
+		attempt++
+
+		overlay := desired
+		if u, ok := desired.(*unstructured.Unstructured); ok {
+			u = u.DeepCopy()
+			if preserved := preserveImmutableFields(live, u); len(preserved) > 0 {
+				log.Infof("keeping the live values of immutable fields %s of resource %s", strings.Join(preserved, ", "), objectKey)
+			}
+			overlay = u
+		}
+		if err := applyOverlay(live, overlay); err != nil {
 			return err
 		}
-		return h.client.Update(context.TODO(), receiver)
+		return h.client.Update(context.TODO(), live)
+	})
+	if err != nil {
+		// The error is returned as is, since apierrors checks such as IsConflict do not see through wrapped errors.
+		log.Errorf("failed to update resource %s %s after %d attempt(s): %v", live.GroupVersionKind().Kind, objectKey, attempt, err)
 	}
 	return err
 }
@@ -0,0 +1,81 @@
+// Copyright 2020 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package helmreconciler
+
+import (
+	"strings"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/discovery"
+
+	iop "istio.io/istio/operator/pkg/apis/istio/v1alpha1"
+	"istio.io/pkg/log"
+)
+
+// istioGroupSuffix is the suffix shared by the API groups of all Istio custom resources.
+const istioGroupSuffix = "istio.io"
+
+// DiscoverResourceTypes returns the given namespaced and cluster scoped resource types, followed by the types of any
+// Istio custom resources served by the cluster that are not already listed. This keeps pruning from missing resource
+// types added by newer Istio CRDs. The IstioOperator type itself is never included. If only some API groups fail
+// discovery, e.g. because an aggregated API is down, the types of the other groups are still returned. If discovery
+// fails altogether, the given types are returned unchanged.
+func DiscoverResourceTypes(d discovery.DiscoveryInterface, namespaced, clusterScoped []schema.GroupVersionKind) ([]schema.GroupVersionKind,
+	[]schema.GroupVersionKind) {
+	_, resources, err := d.ServerGroupsAndResources()
+	switch {
+	case err == nil:
+	case discovery.IsGroupDiscoveryFailedError(err):
+		log.Warnf("failed to discover some resource types, using the types of the other groups: %v", err)
+	default:
+		log.Warnf("failed to discover Istio resource types, using the static list: %v", err)
+		return namespaced, clusterScoped
+	}
+
+	known := make(map[schema.GroupKind]bool)
+	for _, gvk := range append(append([]schema.GroupVersionKind{}, namespaced...), clusterScoped...) {
+		known[gvk.GroupKind()] = true
+	}
+
+	outNamespaced := append([]schema.GroupVersionKind{}, namespaced...)
+	outClusterScoped := append([]schema.GroupVersionKind{}, clusterScoped...)
+	for _, list := range resources {
+		gv, err := schema.ParseGroupVersion(list.GroupVersion)
+		if err != nil || !isIstioGroup(gv.Group) || gv.Group == iop.IstioOperatorGVK.Group {
+			continue
+		}
+		for _, r := range list.APIResources {
+			// Subresources such as status are not types of their own.
+			if strings.Contains(r.Name, "/") {
+				continue
+			}
+			gvk := gv.WithKind(r.Kind)
+			if known[gvk.GroupKind()] {
+				continue
+			}
+			known[gvk.GroupKind()] = true
+			if r.Namespaced {
+				outNamespaced = append(outNamespaced, gvk)
+			} else {
+				outClusterScoped = append(outClusterScoped, gvk)
+			}
+		}
+	}
+	return outNamespaced, outClusterScoped
+}
+
+func isIstioGroup(group string) bool {
+	return group == istioGroupSuffix || strings.HasSuffix(group, "."+istioGroupSuffix)
+}
@@ -0,0 +1,131 @@
+// Copyright 2020 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package helmreconciler
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/discovery/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+var (
+	staticNamespaced = []schema.GroupVersionKind{
+		{Group: "", Version: "v1", Kind: "Service"},
+		{Group: "networking.istio.io", Version: "v1alpha3", Kind: "Gateway"},
+	}
+	staticClusterScoped = []schema.GroupVersionKind{
+		{Group: "rbac.authorization.k8s.io", Version: "v1", Kind: "ClusterRole"},
+	}
+)
+
+func TestDiscoverResourceTypes(t *testing.T) {
+	d := &fake.FakeDiscovery{Fake: &k8stesting.Fake{}}
+	d.Resources = []*metav1.APIResourceList{
+		{
+			GroupVersion: "v1",
+			APIResources: []metav1.APIResource{{Name: "services", Kind: "Service", Namespaced: true}},
+		},
+		{
+			GroupVersion: "networking.istio.io/v1beta1",
+			APIResources: []metav1.APIResource{
+				{Name: "gateways", Kind: "Gateway", Namespaced: true},
+				{Name: "gateways/status", Kind: "Gateway", Namespaced: true},
+			},
+		},
+		{
+			// A CRD unknown to the static list.
+			GroupVersion: "telemetry.istio.io/v1alpha1",
+			APIResources: []metav1.APIResource{{Name: "telemetries", Kind: "Telemetry", Namespaced: true}},
+		},
+		{
+			GroupVersion: "example.istio.io/v1",
+			APIResources: []metav1.APIResource{{Name: "meshwides", Kind: "MeshWide", Namespaced: false}},
+		},
+		{
+			GroupVersion: "install.istio.io/v1alpha1",
+			APIResources: []metav1.APIResource{{Name: "istiooperators", Kind: "IstioOperator", Namespaced: true}},
+		},
+		{
+			GroupVersion: "example.com/v1",
+			APIResources: []metav1.APIResource{{Name: "widgets", Kind: "Widget", Namespaced: true}},
+		},
+	}
+
+	gotNamespaced, gotClusterScoped := DiscoverResourceTypes(d, staticNamespaced, staticClusterScoped)
+
+	wantNamespaced := append(append([]schema.GroupVersionKind{}, staticNamespaced...),
+		schema.GroupVersionKind{Group: "telemetry.istio.io", Version: "v1alpha1", Kind: "Telemetry"})
+	wantClusterScoped := append(append([]schema.GroupVersionKind{}, staticClusterScoped...),
+		schema.GroupVersionKind{Group: "example.istio.io", Version: "v1", Kind: "MeshWide"})
+	if !reflect.DeepEqual(gotNamespaced, wantNamespaced) {
+		t.Errorf("got namespaced %v, want %v", gotNamespaced, wantNamespaced)
+	}
+	if !reflect.DeepEqual(gotClusterScoped, wantClusterScoped) {
+		t.Errorf("got cluster scoped %v, want %v", gotClusterScoped, wantClusterScoped)
+	}
+}
+
+// failingDiscovery is a discovery client for a cluster whose API discovery is unavailable.
+type failingDiscovery struct {
+	fake.FakeDiscovery
+}
+
+func (failingDiscovery) ServerGroupsAndResources() ([]*metav1.APIGroup, []*metav1.APIResourceList, error) {
+	return nil, nil, fmt.Errorf("discovery unavailable")
+}
+
+func TestDiscoverResourceTypesFallback(t *testing.T) {
+	gotNamespaced, gotClusterScoped := DiscoverResourceTypes(&failingDiscovery{}, staticNamespaced, staticClusterScoped)
+	if !reflect.DeepEqual(gotNamespaced, staticNamespaced) || !reflect.DeepEqual(gotClusterScoped, staticClusterScoped) {
+		t.Errorf("got %v %v, want the static types", gotNamespaced, gotClusterScoped)
+	}
+}
+
+// partialDiscovery is a discovery client for a cluster where the discovery of one API group fails, e.g. an aggregated
+// API that is down.
+type partialDiscovery struct {
+	fake.FakeDiscovery
+}
+
+func (partialDiscovery) ServerGroupsAndResources() ([]*metav1.APIGroup, []*metav1.APIResourceList, error) {
+	resources := []*metav1.APIResourceList{
+		{
+			GroupVersion: "telemetry.istio.io/v1alpha1",
+			APIResources: []metav1.APIResource{{Name: "telemetries", Kind: "Telemetry", Namespaced: true}},
+		},
+	}
+	err := &discovery.ErrGroupDiscoveryFailed{
+		Groups: map[schema.GroupVersion]error{{Group: "metrics.k8s.io", Version: "v1beta1"}: fmt.Errorf("service unavailable")},
+	}
+	return nil, resources, err
+}
+
+func TestDiscoverResourceTypesPartial(t *testing.T) {
+	gotNamespaced, gotClusterScoped := DiscoverResourceTypes(&partialDiscovery{}, staticNamespaced, staticClusterScoped)
+	wantNamespaced := append(append([]schema.GroupVersionKind{}, staticNamespaced...),
+		schema.GroupVersionKind{Group: "telemetry.istio.io", Version: "v1alpha1", Kind: "Telemetry"})
+	if !reflect.DeepEqual(gotNamespaced, wantNamespaced) {
+		t.Errorf("got namespaced %v, want %v", gotNamespaced, wantNamespaced)
+	}
+	if !reflect.DeepEqual(gotClusterScoped, staticClusterScoped) {
+		t.Errorf("got cluster scoped %v, want %v", gotClusterScoped, staticClusterScoped)
+	}
+}
@@ -0,0 +1,116 @@
+// Copyright 2020 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package helmreconciler
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/api/admissionregistration/v1beta1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	iop "istio.io/istio/operator/pkg/apis/istio/v1alpha1"
+	"istio.io/istio/operator/pkg/object"
+	"istio.io/istio/operator/pkg/util"
+	"istio.io/pkg/log"
+)
+
+// validateIstioResources sends the Istio custom resources in manifests to the API server in a server-side dry run, so
+// that resources rejected by a validating webhook are reported before anything is applied. It returns the errors by
+// component name, and is skipped when no validating webhook for Istio resources is installed yet, e.g. on the first
+// install.
+func (h *HelmReconciler) validateIstioResources(manifests ChartManifestsMap) map[string]error {
+	installed, err := h.istioValidatingWebhookInstalled()
+	if err != nil {
+		log.Warnf("failed to look up validating webhooks, skipping validation of Istio resources: %v", err)
+		return nil
+	}
+	if !installed {
+		log.Info("no validating webhook for Istio resources is installed, skipping validation of Istio resources")
+		return nil
+	}
+
+	errs := make(map[string]error)
+	for c, ms := range manifests {
+		var cerrs util.Errors
+		for _, m := range ms {
+			objs, err := object.ParseK8sObjectsFromYAMLManifest(m.Content)
+			if err != nil {
+				cerrs = util.AppendErr(cerrs, err)
+				continue
+			}
+			for _, o := range objs {
+				if !isIstioGroup(o.Group) || o.Group == iop.IstioOperatorGVK.Group {
+					continue
+				}
+				cerrs = util.AppendErr(cerrs, h.dryRunApply(o))
+			}
+		}
+		if err := cerrs.ToError(); err != nil {
+			errs[c] = err
+		}
+	}
+	return errs
+}
+
+// istioValidatingWebhookInstalled reports whether any validating webhook applies to Istio resources.
+func (h *HelmReconciler) istioValidatingWebhookInstalled() (bool, error) {
+	webhooks := &v1beta1.ValidatingWebhookConfigurationList{}
+	if err := h.client.List(context.TODO(), webhooks); err != nil {
+		return false, err
+	}
+	for _, wh := range webhooks.Items {
+		for _, w := range wh.Webhooks {
+			for _, r := range w.Rules {
+				for _, g := range r.APIGroups {
+					if g == "*" || isIstioGroup(g) {
+						return true, nil
+					}
+				}
+			}
+		}
+	}
+	return false, nil
+}
+
+// dryRunApply creates or updates o in a server-side dry run. It returns an error naming o if the API server rejects
+// it, or if the dry run itself is forbidden. Other failures, e.g. a CRD that is not installed yet, are only logged
+// since the real apply will report them.
+func (h *HelmReconciler) dryRunApply(o *object.K8sObject) error {
+	obj := o.UnstructuredObject()
+	objectKey, _ := client.ObjectKeyFromObject(obj)
+
+	err := h.client.Create(context.TODO(), obj, client.DryRunAll)
+	if apierrors.IsAlreadyExists(err) {
+		live := obj.DeepCopy()
+		if err = h.client.Get(context.TODO(), objectKey, live); err == nil {
+			obj.SetResourceVersion(live.GetResourceVersion())
+			err = h.client.Update(context.TODO(), obj, client.DryRunAll)
+		}
+	}
+	switch {
+	case err == nil:
+		return nil
+	case apierrors.IsInvalid(err) || apierrors.IsBadRequest(err):
+		// Validating webhooks deny requests with a 400 status unless they set a code.
+		return fmt.Errorf("%s %s was rejected: %v", o.Kind, objectKey, err)
+	case apierrors.IsForbidden(err):
+		return fmt.Errorf("dry run of %s %s is forbidden, so it could not be validated: %v", o.Kind, objectKey, err)
+	default:
+		log.Warnf("failed to validate %s %s in a dry run: %v", o.Kind, objectKey, err)
+		return nil
+	}
+}
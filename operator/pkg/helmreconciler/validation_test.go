@@ -0,0 +1,193 @@
+// Copyright 2020 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package helmreconciler
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"k8s.io/api/admissionregistration/v1beta1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"istio.io/api/operator/v1alpha1"
+)
+
+// webhookClient rejects dry runs of objects named "rejected", like a validating webhook denying the request, and
+// forbids dry runs of objects named "forbidden", like RBAC denying the request.
+type webhookClient struct {
+	client.Client
+	dryRuns int
+}
+
+func (c *webhookClient) Create(ctx context.Context, obj runtime.Object, opts ...client.CreateOption) error {
+	o := &client.CreateOptions{}
+	o.ApplyOptions(opts)
+	if len(o.DryRun) > 0 {
+		c.dryRuns++
+		switch u := obj.(*unstructured.Unstructured); u.GetName() {
+		case "rejected":
+			return apierrors.NewBadRequest(`admission webhook "validation.istio.io" denied the request: host must be set`)
+		case "forbidden":
+			return apierrors.NewForbidden(schema.GroupResource{Group: "networking.istio.io", Resource: "destinationrules"},
+				u.GetName(), errors.New(`User "system:serviceaccount:istio-operator:istio-operator" cannot create resource`))
+		}
+	}
+	return c.Client.Create(ctx, obj, opts...)
+}
+
+const validationTestManifest = `
+apiVersion: networking.istio.io/v1alpha3
+kind: DestinationRule
+metadata:
+  name: accepted
+  namespace: istio-system
+spec:
+  host: istiod.istio-system.svc.cluster.local
+---
+apiVersion: networking.istio.io/v1alpha3
+kind: DestinationRule
+metadata:
+  name: rejected
+  namespace: istio-system
+spec:
+  trafficPolicy: {}
+---
+apiVersion: networking.istio.io/v1alpha3
+kind: DestinationRule
+metadata:
+  name: forbidden
+  namespace: istio-system
+spec:
+  host: istiod.istio-system.svc.cluster.local
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: istio
+  namespace: istio-system
+`
+
+func TestValidateIstioResources(t *testing.T) {
+	istioWebhook := &v1beta1.ValidatingWebhookConfiguration{
+		ObjectMeta: metav1.ObjectMeta{Name: "istio-galley"},
+		Webhooks: []v1beta1.ValidatingWebhook{{
+			Name:  "validation.istio.io",
+			Rules: []v1beta1.RuleWithOperations{{Rule: v1beta1.Rule{APIGroups: []string{"networking.istio.io"}}}},
+		}},
+	}
+	otherWebhook := &v1beta1.ValidatingWebhookConfiguration{
+		ObjectMeta: metav1.ObjectMeta{Name: "other"},
+		Webhooks: []v1beta1.ValidatingWebhook{{
+			Name:  "validation.example.com",
+			Rules: []v1beta1.RuleWithOperations{{Rule: v1beta1.Rule{APIGroups: []string{"example.com"}}}},
+		}},
+	}
+	manifests := ChartManifestsMap{"Pilot": {{Name: "Pilot", Content: validationTestManifest}}}
+
+	tests := []struct {
+		desc        string
+		objs        []runtime.Object
+		wantDryRuns int
+		wantErrs    []string
+	}{
+		{
+			desc: "NoWebhook",
+		},
+		{
+			desc: "NoIstioWebhook",
+			objs: []runtime.Object{otherWebhook},
+		},
+		{
+			desc:        "RejectedResource",
+			objs:        []runtime.Object{istioWebhook},
+			wantDryRuns: 3,
+			wantErrs: []string{
+				"DestinationRule istio-system/rejected was rejected",
+				"dry run of DestinationRule istio-system/forbidden is forbidden",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			cl := &webhookClient{Client: fake.NewFakeClientWithScheme(scheme.Scheme, tt.objs...)}
+			h := &HelmReconciler{client: cl}
+
+			errs := h.validateIstioResources(manifests)
+			err := errs["Pilot"]
+			switch {
+			case len(tt.wantErrs) == 0 && len(errs) != 0:
+				t.Fatalf("got errors %v, want none", errs)
+			case len(tt.wantErrs) != 0 && err == nil:
+				t.Fatalf("got errors %v, want errors for Pilot", errs)
+			}
+			for _, want := range tt.wantErrs {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("got error %v, want %q", err, want)
+				}
+			}
+			if err != nil && strings.Contains(err.Error(), "forbidden was rejected") {
+				t.Errorf("got error %v, want the forbidden dry run not to be reported as a rejection", err)
+			}
+			if cl.dryRuns != tt.wantDryRuns {
+				t.Errorf("got %d dry runs, want %d", cl.dryRuns, tt.wantDryRuns)
+			}
+
+			accepted := &unstructured.Unstructured{}
+			accepted.SetGroupVersionKind(schema.GroupVersionKind{Group: "networking.istio.io", Version: "v1alpha3", Kind: "DestinationRule"})
+			if err := cl.Get(context.TODO(), client.ObjectKey{Namespace: "istio-system", Name: "accepted"}, accepted); !apierrors.IsNotFound(err) {
+				t.Errorf("got error %v getting the validated resource, want it not to be created", err)
+			}
+		})
+	}
+}
+
+// statusListener records the status passed to EndReconcile.
+type statusListener struct {
+	*DefaultRenderingListener
+	status *v1alpha1.InstallStatus
+}
+
+func (l *statusListener) EndReconcile(_ runtime.Object, status *v1alpha1.InstallStatus) error {
+	l.status = status
+	return nil
+}
+
+func TestEndReconcileWithValidationErrors(t *testing.T) {
+	listener := &statusListener{DefaultRenderingListener: &DefaultRenderingListener{}}
+	h := &HelmReconciler{customizer: &SimpleRenderingCustomizer{ListenerValue: listener}}
+
+	err := h.endReconcileWithValidationErrors(map[string]error{"Pilot": errors.New("DestinationRule istio-system/rejected was rejected")})
+	if err == nil || !strings.Contains(err.Error(), "rejected was rejected") {
+		t.Fatalf("got error %v, want the validation error", err)
+	}
+	if listener.status == nil {
+		t.Fatal("EndReconcile was not called")
+	}
+	if listener.status.Status != v1alpha1.InstallStatus_ERROR {
+		t.Errorf("got status %v, want %v", listener.status.Status, v1alpha1.InstallStatus_ERROR)
+	}
+	if cs := listener.status.ComponentStatus["Pilot"]; cs == nil || cs.Status != v1alpha1.InstallStatus_ERROR {
+		t.Errorf("got Pilot status %v, want %v", cs, v1alpha1.InstallStatus_ERROR)
+	}
+}
@@ -15,6 +15,7 @@
 package manifest
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"io"
@@ -32,12 +33,12 @@ import (
 	v1 "k8s.io/api/core/v1"
 	apiextensionsv1beta1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1"
 	apiextensionsclient "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/fields"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/runtime/serializer"
-	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/kubernetes/scheme"
 
@@ -94,10 +95,20 @@ type ComponentApplyOutput struct {
 	Err error
 	// Manifest is the manifest applied to the cluster.
 	Manifest string
+	// AppliedObjects is the number of objects applied to the cluster.
+	AppliedObjects int
 }
 
 type CompositeOutput map[name.ComponentName]*ComponentApplyOutput
 
+// kubectlClient runs kubectl commands. It is implemented by *kubectlcmd.Client, and faked in tests.
+type kubectlClient interface {
+	Apply(manifest string, opts *kubectlcmd.Options) (string, string, error)
+	Delete(manifest string, opts *kubectlcmd.Options) (string, string, error)
+	GetAll(opts *kubectlcmd.Options) (string, string, error)
+	Version(opts *kubectlcmd.Options) (string, string, error)
+}
+
 type componentNameToListMap map[name.ComponentName][]name.ComponentName
 type componentTree map[name.ComponentName]interface{}
 
@@ -126,7 +137,8 @@ var (
 
 	installTree      = make(componentTree)
 	dependencyWaitCh = make(map[name.ComponentName]chan struct{})
-	kubectl          = kubectlcmd.New()
+
+	kubectl kubectlClient = kubectlcmd.New()
 
 	k8sRESTConfig     *rest.Config
 	currentKubeconfig string
@@ -436,7 +448,7 @@ func ApplyManifest(componentName name.ComponentName, manifestStr, version string
 	}
 	logAndPrint("%s Finished applying manifest for component %s.", mark, componentName)
 	if applyErr != nil {
-		return buildComponentApplyOutput(stdout, stderr, appliedObjects, err), appliedObjects
+		return buildComponentApplyOutput(stdout, stderr, appliedObjects, applyErr), appliedObjects
 	}
 	return buildComponentApplyOutput(stdout, stderr, appliedObjects, err), appliedObjects
 }
@@ -498,9 +510,7 @@ func applyObjects(objs object.K8sObjects, opts *kubectlcmd.Options, stdout, stde
 		return stdout, stderr, nil
 	}
 
-	objs.Sort(DefaultObjectOrder())
-
-	mns, err := objs.JSONManifest()
+	mns, err := object.SortByApplyOrder(objs).JSONManifest()
 	if err != nil {
 		return stdout, stderr, err
 	}
@@ -512,72 +522,25 @@ func applyObjects(objs object.K8sObjects, opts *kubectlcmd.Options, stdout, stde
 	return stdout, stderr, err
 }
 
+// buildComponentApplyOutput returns the output of a component apply. No objects are counted as applied if the apply
+// failed, since it is not known which of them made it to the cluster.
 func buildComponentApplyOutput(stdout string, stderr string, objects object.K8sObjects, err error) *ComponentApplyOutput {
 	manifest, _ := objects.YAMLManifest()
-	return &ComponentApplyOutput{
+	out := &ComponentApplyOutput{
 		Stdout:   stdout,
 		Stderr:   stderr,
 		Manifest: manifest,
 		Err:      err,
 	}
-}
-
-func istioCustomResources(group string) bool {
-	switch group {
-	case "config.istio.io",
-		"rbac.istio.io",
-		"security.istio.io",
-		"authentication.istio.io",
-		"networking.istio.io":
-		return true
+	if err == nil {
+		out.AppliedObjects = len(objects)
 	}
-	return false
+	return out
 }
 
 // DefaultObjectOrder is default sorting function used to sort k8s objects.
 func DefaultObjectOrder() func(o *object.K8sObject) int {
-	return func(o *object.K8sObject) int {
-		gk := o.Group + "/" + o.Kind
-		switch {
-		// Create CRDs asap - both because they are slow and because we will likely create instances of them soon
-		case gk == "apiextensions.k8s.io/CustomResourceDefinition":
-			return -1000
-
-			// We need to create ServiceAccounts, Roles before we bind them with a RoleBinding
-		case gk == "/ServiceAccount" || gk == "rbac.authorization.k8s.io/ClusterRole":
-			return 1
-		case gk == "rbac.authorization.k8s.io/ClusterRoleBinding":
-			return 2
-
-			// validatingwebhookconfiguration is configured to FAIL-OPEN in the default install. For the
-			// re-install case we want to apply the validatingwebhookconfiguration first to reset any
-			// orphaned validatingwebhookconfiguration that is FAIL-CLOSE.
-		case gk == "admissionregistration.k8s.io/ValidatingWebhookConfiguration":
-			return 3
-
-		case istioCustomResources(o.Group):
-			return 4
-
-			// Pods might need configmap or secrets - avoid backoff by creating them first
-		case gk == "/ConfigMap" || gk == "/Secrets":
-			return 100
-
-			// Create the pods after we've created other things they might be waiting for
-		case gk == "extensions/Deployment" || gk == "app/Deployment":
-			return 1000
-
-			// Autoscalers typically act on a deployment
-		case gk == "autoscaling/HorizontalPodAutoscaler":
-			return 1001
-
-			// Create services late - after pods have been started
-		case gk == "/Service":
-			return 10000
-
-		default:
-			return 1000
-		}
-	}
+	return object.ApplyOrderScore
 }
 
 func cRDKindObjects(objects object.K8sObjects) object.K8sObjects {
@@ -656,7 +619,7 @@ func waitForCRDs(objects object.K8sObjects, stdout string, dryRun bool) error {
 		crdNames = append(crdNames, o.Name)
 	}
 
-	errPoll := wait.Poll(cRDPollInterval, cRDPollTimeout, func() (bool, error) {
+	errPoll := util.PollUntil(context.TODO(), cRDPollInterval, cRDPollTimeout, func() (bool, error) {
 	descriptor:
 		for _, crdName := range crdNames {
 			crd, errGet := cs.ApiextensionsV1beta1().CustomResourceDefinitions().Get(crdName, metav1.GetOptions{})
@@ -707,7 +670,7 @@ func WaitForResources(objects object.K8sObjects, opts *kubectlcmd.Options) error
 
 	var notReady []string
 
-	errPoll := wait.Poll(2*time.Second, opts.WaitTimeout, func() (bool, error) {
+	errPoll := util.PollAtInterval(context.TODO(), 2*time.Second, opts.WaitTimeout, func() (bool, error) {
 		pods := []v1.Pod{}
 		deployments := []deployment{}
 		namespaces := []v1.Namespace{}
@@ -801,6 +764,60 @@ func WaitForResources(objects object.K8sObjects, opts *kubectlcmd.Options) error
 	return nil
 }
 
+// WaitForDeletion polls until the namespaces, workloads and services among objects no longer exist, or until
+// opts.WaitTimeout is reached. Other kinds of objects are removed synchronously by the API server.
+func WaitForDeletion(objects object.K8sObjects, opts *kubectlcmd.Options) error {
+	if opts.DryRun {
+		logAndPrint("Not waiting for resources to be deleted in dry run mode.")
+		return nil
+	}
+
+	cs, err := kubernetes.NewForConfig(k8sRESTConfig)
+	if err != nil {
+		return fmt.Errorf("k8s client error: %s", err)
+	}
+
+	var remaining []string
+	errPoll := util.PollUntil(context.TODO(), time.Second, opts.WaitTimeout, func() (bool, error) {
+		remaining = nil
+		for _, o := range objects {
+			var err error
+			switch o.Kind {
+			case "Namespace":
+				_, err = cs.CoreV1().Namespaces().Get(o.Name, metav1.GetOptions{})
+			case "Pod":
+				_, err = cs.CoreV1().Pods(o.Namespace).Get(o.Name, metav1.GetOptions{})
+			case "Service":
+				_, err = cs.CoreV1().Services(o.Namespace).Get(o.Name, metav1.GetOptions{})
+			case "Deployment":
+				_, err = cs.AppsV1().Deployments(o.Namespace).Get(o.Name, metav1.GetOptions{})
+			case "DaemonSet":
+				_, err = cs.AppsV1().DaemonSets(o.Namespace).Get(o.Name, metav1.GetOptions{})
+			case "StatefulSet":
+				_, err = cs.AppsV1().StatefulSets(o.Namespace).Get(o.Name, metav1.GetOptions{})
+			default:
+				continue
+			}
+			switch {
+			case apierrors.IsNotFound(err):
+			case err != nil:
+				return false, err
+			default:
+				remaining = append(remaining, fmt.Sprintf("%s/%s/%s", o.Kind, o.Namespace, o.Name))
+			}
+		}
+		if len(remaining) > 0 {
+			logAndPrint("  Waiting for resources to be deleted...")
+		}
+		return len(remaining) == 0, nil
+	})
+
+	if errPoll != nil {
+		return fmt.Errorf("resources not deleted after %v: %v\n%s", opts.WaitTimeout, errPoll, strings.Join(remaining, "\n"))
+	}
+	return nil
+}
+
 func getPods(client kubernetes.Interface, namespace string, selector map[string]string) ([]v1.Pod, error) {
 	list, err := client.CoreV1().Pods(namespace).List(metav1.ListOptions{
 		FieldSelector: fields.Everything().String(),
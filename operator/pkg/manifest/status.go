@@ -0,0 +1,133 @@
+// Copyright 2020 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package manifest
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+
+	"istio.io/istio/operator/pkg/name"
+)
+
+// InstallStatusConfigMapName is the name of the ConfigMap in the Istio namespace recording the components installed
+// by istioctl. Reconciles of the operator controller do not record it; they report status on the IstioOperator.
+const InstallStatusConfigMapName = "istio-install-status"
+
+// ComponentInstallStatus records the installation of a single component.
+type ComponentInstallStatus struct {
+	// Version is the version of the component that was applied.
+	Version string `json:"version"`
+	// AppliedObjects is the number of objects that were applied for the component, zero if the apply failed.
+	AppliedObjects int `json:"appliedObjects"`
+	// Timestamp is the time the component was applied.
+	Timestamp time.Time `json:"timestamp"`
+	// Error is the apply error for the component, if any.
+	Error string `json:"error,omitempty"`
+}
+
+// InstallStatus is the install status of each component, keyed by component name.
+type InstallStatus map[name.ComponentName]*ComponentInstallStatus
+
+// NewInstallStatus returns the install status of the components in out, applied at the given version and time.
+// Components with no applied objects and no error are skipped.
+func NewInstallStatus(out CompositeOutput, version string, timestamp time.Time) InstallStatus {
+	ret := make(InstallStatus)
+	for c, o := range out {
+		if o == nil || (o.AppliedObjects == 0 && o.Err == nil) {
+			continue
+		}
+		cs := &ComponentInstallStatus{
+			Version:        version,
+			AppliedObjects: o.AppliedObjects,
+			Timestamp:      timestamp.UTC(),
+		}
+		if o.Err != nil {
+			cs.Error = o.Err.Error()
+		}
+		ret[c] = cs
+	}
+	return ret
+}
+
+// ToConfigMap returns the ConfigMap recording s in the given namespace. Each component is stored as JSON under its
+// name.
+func (s InstallStatus) ToConfigMap(namespace string) (*v1.ConfigMap, error) {
+	cm := &v1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      InstallStatusConfigMapName,
+			Namespace: namespace,
+			Labels:    map[string]string{operatorLabelStr: "istioctl"},
+		},
+		Data: make(map[string]string, len(s)),
+	}
+	for c, cs := range s {
+		b, err := json.Marshal(cs)
+		if err != nil {
+			return nil, err
+		}
+		cm.Data[string(c)] = string(b)
+	}
+	return cm, nil
+}
+
+// InstallStatusFromConfigMap returns the install status recorded in cm.
+func InstallStatusFromConfigMap(cm *v1.ConfigMap) (InstallStatus, error) {
+	ret := make(InstallStatus, len(cm.Data))
+	for c, data := range cm.Data {
+		cs := &ComponentInstallStatus{}
+		if err := json.Unmarshal([]byte(data), cs); err != nil {
+			return nil, fmt.Errorf("could not parse install status of component %s: %v", c, err)
+		}
+		ret[name.ComponentName(c)] = cs
+	}
+	return ret, nil
+}
+
+// WriteInstallStatus records status in the install status ConfigMap of the given namespace, replacing any status
+// recorded by a previous install.
+func WriteInstallStatus(client kubernetes.Interface, namespace string, status InstallStatus) error {
+	cm, err := status.ToConfigMap(namespace)
+	if err != nil {
+		return err
+	}
+	configMaps := client.CoreV1().ConfigMaps(namespace)
+	existing, err := configMaps.Get(InstallStatusConfigMapName, metav1.GetOptions{})
+	switch {
+	case errors.IsNotFound(err):
+		_, err = configMaps.Create(cm)
+	case err == nil:
+		cm.ResourceVersion = existing.ResourceVersion
+		_, err = configMaps.Update(cm)
+	}
+	if err != nil {
+		return fmt.Errorf("could not write install status to %s/%s: %v", namespace, InstallStatusConfigMapName, err)
+	}
+	return nil
+}
+
+// ReadInstallStatus returns the install status recorded in the given namespace.
+func ReadInstallStatus(client kubernetes.Interface, namespace string) (InstallStatus, error) {
+	cm, err := client.CoreV1().ConfigMaps(namespace).Get(InstallStatusConfigMapName, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("could not read install status from %s/%s: %v", namespace, InstallStatusConfigMapName, err)
+	}
+	return InstallStatusFromConfigMap(cm)
+}
@@ -0,0 +1,135 @@
+// Copyright 2020 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package manifest
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"istio.io/istio/operator/pkg/kubectlcmd"
+	"istio.io/istio/operator/pkg/name"
+)
+
+func TestWriteInstallStatus(t *testing.T) {
+	client := fake.NewSimpleClientset()
+	applied := time.Date(2020, 2, 3, 4, 5, 6, 0, time.UTC)
+
+	out := CompositeOutput{
+		name.PilotComponentName:          &ComponentApplyOutput{AppliedObjects: 12},
+		name.IngressComponentName:        &ComponentApplyOutput{Err: errors.New("apply failed")},
+		name.EgressComponentName:         &ComponentApplyOutput{},
+		name.IstioBaseComponentName:      &ComponentApplyOutput{AppliedObjects: 40},
+		name.ComponentName("Deprecated"): nil,
+	}
+	if err := WriteInstallStatus(client, "istio-system", NewInstallStatus(out, "1.5.0", applied)); err != nil {
+		t.Fatal(err)
+	}
+
+	cm, err := client.CoreV1().ConfigMaps("istio-system").Get(InstallStatusConfigMapName, metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantData := map[string]string{
+		string(name.PilotComponentName):     `{"version":"1.5.0","appliedObjects":12,"timestamp":"2020-02-03T04:05:06Z"}`,
+		string(name.IngressComponentName):   `{"version":"1.5.0","appliedObjects":0,"timestamp":"2020-02-03T04:05:06Z","error":"apply failed"}`,
+		string(name.IstioBaseComponentName): `{"version":"1.5.0","appliedObjects":40,"timestamp":"2020-02-03T04:05:06Z"}`,
+	}
+	if !reflect.DeepEqual(cm.Data, wantData) {
+		t.Errorf("got ConfigMap data %v, want %v", cm.Data, wantData)
+	}
+
+	// A later install replaces the recorded status.
+	out = CompositeOutput{name.PilotComponentName: &ComponentApplyOutput{AppliedObjects: 10}}
+	if err := WriteInstallStatus(client, "istio-system", NewInstallStatus(out, "1.5.1", applied)); err != nil {
+		t.Fatal(err)
+	}
+	got, err := ReadInstallStatus(client, "istio-system")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := InstallStatus{
+		name.PilotComponentName: &ComponentInstallStatus{Version: "1.5.1", AppliedObjects: 10, Timestamp: applied},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got status %v, want %v", got, want)
+	}
+}
+
+func TestReadInstallStatusMissing(t *testing.T) {
+	if _, err := ReadInstallStatus(fake.NewSimpleClientset(), "istio-system"); err == nil {
+		t.Error("expected an error for a missing install status")
+	}
+}
+
+// fakeKubectl accepts every apply, except of manifests containing "failing".
+type fakeKubectl struct {
+	kubectlClient
+}
+
+func (fakeKubectl) Apply(manifest string, _ *kubectlcmd.Options) (string, string, error) {
+	if strings.Contains(manifest, "failing") {
+		return "", "error: admission denied", errors.New("exit status 1")
+	}
+	return "applied", "", nil
+}
+
+const statusTestManifest = `
+apiVersion: v1
+kind: Service
+metadata:
+  name: %s
+  namespace: istio-system
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: %s
+  namespace: istio-system
+`
+
+func TestApplyManifestInstallStatus(t *testing.T) {
+	defer func(k kubectlClient) { kubectl = k }(kubectl)
+	kubectl = fakeKubectl{}
+	applied := time.Date(2020, 2, 3, 4, 5, 6, 0, time.UTC)
+
+	opts := kubectlcmd.Options{DryRun: true}
+	out := CompositeOutput{}
+	out[name.PilotComponentName], _ = ApplyManifest(name.PilotComponentName,
+		fmt.Sprintf(statusTestManifest, "istiod", "istio"), "1.5.0", opts)
+	out[name.IngressComponentName], _ = ApplyManifest(name.IngressComponentName,
+		fmt.Sprintf(statusTestManifest, "istio-ingressgateway", "failing"), "1.5.0", opts)
+
+	client := fake.NewSimpleClientset()
+	if err := WriteInstallStatus(client, "istio-system", NewInstallStatus(out, "1.5.0", applied)); err != nil {
+		t.Fatal(err)
+	}
+	got, err := ReadInstallStatus(client, "istio-system")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cs := got[name.PilotComponentName]; cs == nil || cs.AppliedObjects != 2 || cs.Error != "" {
+		t.Errorf("got Pilot status %+v, want 2 applied objects and no error", cs)
+	}
+	if cs := got[name.IngressComponentName]; cs == nil || cs.AppliedObjects != 0 || cs.Error == "" {
+		t.Errorf("got failed Ingress status %+v, want no applied objects and an error", cs)
+	}
+}
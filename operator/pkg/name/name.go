@@ -97,6 +97,22 @@ var (
 	// ValuesEnablementPathMap defines a mapping between legacy values enablement paths and the corresponding enablement
 	// paths in IstioOperator.
 	ValuesEnablementPathMap = make(map[string]string)
+
+	// userFacingComponentNames are the names of components shown to users, e.g. in installation progress.
+	userFacingComponentNames = map[ComponentName]string{
+		IstioBaseComponentName:          "Istio core",
+		PilotComponentName:              "Istiod",
+		GalleyComponentName:             "Galley",
+		PolicyComponentName:             "Policy",
+		TelemetryComponentName:          "Telemetry",
+		CitadelComponentName:            "Citadel",
+		CNIComponentName:                "CNI",
+		IngressComponentName:            "Ingress gateways",
+		EgressComponentName:             "Egress gateways",
+		AddonComponentName:              "Addons",
+		IstioOperatorComponentName:      "Istio operator",
+		IstioOperatorCustomResourceName: "Istio operator CR",
+	}
 )
 
 func init() {
@@ -169,6 +185,36 @@ func TitleCase(n ComponentName) ComponentName {
 	return ComponentName(strings.ToUpper(s[0:1]) + s[1:])
 }
 
+// UserFacingComponentName returns the name of the given component that is shown to users. Components without a
+// user facing name, such as individual addon components, are shown with their component name; the addons as a whole
+// are shown as "Addons".
+func UserFacingComponentName(name ComponentName) string {
+	if ret, ok := userFacingComponentNames[name]; ok {
+		return ret
+	}
+	return string(name)
+}
+
+// AllComponentsUserFacing returns the user facing names of all components which have one. The returned map is a copy
+// and may be modified by the caller.
+func AllComponentsUserFacing() map[ComponentName]string {
+	ret := make(map[ComponentName]string, len(userFacingComponentNames))
+	for cn, n := range userFacingComponentNames {
+		ret[cn] = n
+	}
+	return ret
+}
+
+// AllComponentsByUserFacingName returns the components which have a user facing name, keyed by that name. It is the
+// inverse of AllComponentsUserFacing.
+func AllComponentsByUserFacingName() map[string]ComponentName {
+	ret := make(map[string]ComponentName, len(userFacingComponentNames))
+	for cn, n := range userFacingComponentNames {
+		ret[n] = cn
+	}
+	return ret
+}
+
 // loadComponentNamesConfig loads a config that defines version specific components names, such as legacy components
 // names that may not otherwise exist in the code.
 func loadComponentNamesConfig() error {
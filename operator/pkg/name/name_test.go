@@ -126,3 +126,41 @@ a:
 		})
 	}
 }
+
+func TestAllComponentsUserFacing(t *testing.T) {
+	known := append([]ComponentName{
+		IngressComponentName,
+		EgressComponentName,
+		AddonComponentName,
+		IstioOperatorComponentName,
+		IstioOperatorCustomResourceName,
+	}, AllCoreComponentNames...)
+
+	userFacing := AllComponentsUserFacing()
+	byUserFacingName := AllComponentsByUserFacingName()
+	if len(userFacing) != len(byUserFacingName) {
+		t.Errorf("got %d user facing names for %d components, want them to be distinct", len(byUserFacingName), len(userFacing))
+	}
+	for _, cn := range known {
+		n, ok := userFacing[cn]
+		if !ok || n == "" {
+			t.Errorf("component %s has no user facing name", cn)
+			continue
+		}
+		if got := UserFacingComponentName(cn); got != n {
+			t.Errorf("UserFacingComponentName(%s) = %s, want %s", cn, got, n)
+		}
+		if got := byUserFacingName[n]; got != cn {
+			t.Errorf("got component %s for user facing name %s, want %s", got, n, cn)
+		}
+	}
+
+	if got := UserFacingComponentName("Prometheus"); got != "Prometheus" {
+		t.Errorf("got user facing name %s for an addon, want Prometheus", got)
+	}
+
+	userFacing[PilotComponentName] = "changed"
+	if got := UserFacingComponentName(PilotComponentName); got != "Istiod" {
+		t.Errorf("modifying the returned map changed the user facing name of Pilot to %s", got)
+	}
+}
@@ -0,0 +1,104 @@
+// Copyright 2020 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+// ApplyOrderScore returns the relative position of o in the standard Istio install order. Objects with a lower score
+// must be applied before objects with a higher score.
+func ApplyOrderScore(o *K8sObject) int {
+	gk := o.Group + "/" + o.Kind
+	switch {
+	// Namespaces must exist before anything can be created in them.
+	case gk == "/Namespace":
+		return -2000
+
+	// Create CRDs asap - both because they are slow and because we will likely create instances of them soon
+	case gk == "apiextensions.k8s.io/CustomResourceDefinition":
+		return -1000
+
+	// We need to create ServiceAccounts, Roles before we bind them with a RoleBinding
+	case gk == "/ServiceAccount" || gk == "rbac.authorization.k8s.io/ClusterRole" || gk == "rbac.authorization.k8s.io/Role":
+		return 1
+	case gk == "rbac.authorization.k8s.io/ClusterRoleBinding" || gk == "rbac.authorization.k8s.io/RoleBinding":
+		return 2
+
+	// validatingwebhookconfiguration is configured to FAIL-OPEN in the default install. For the
+	// re-install case we want to apply the validatingwebhookconfiguration first to reset any
+	// orphaned validatingwebhookconfiguration that is FAIL-CLOSE.
+	case gk == "admissionregistration.k8s.io/ValidatingWebhookConfiguration":
+		return 3
+
+	case IsIstioCustomResourceGroup(o.Group):
+		return 4
+
+	// Pods might need configmap or secrets - avoid backoff by creating them first
+	case gk == "/ConfigMap" || gk == "/Secret" || gk == "/Secrets":
+		return 100
+
+	// Create the pods after we've created other things they might be waiting for
+	case gk == "extensions/Deployment" || gk == "apps/Deployment" || gk == "app/Deployment":
+		return 1000
+
+	// Autoscalers typically act on a deployment
+	case gk == "autoscaling/HorizontalPodAutoscaler":
+		return 1001
+
+	// Create services late - after pods have been started
+	case gk == "/Service":
+		return 10000
+
+	// Mutating webhooks call into the workloads and services above, so register them last.
+	case gk == "admissionregistration.k8s.io/MutatingWebhookConfiguration":
+		return 20000
+
+	default:
+		return 1000
+	}
+}
+
+// IsIstioCustomResourceGroup reports whether group is one of the API groups of Istio custom resources.
+func IsIstioCustomResourceGroup(group string) bool {
+	switch group {
+	case "config.istio.io",
+		"rbac.istio.io",
+		"security.istio.io",
+		"authentication.istio.io",
+		"networking.istio.io":
+		return true
+	}
+	return false
+}
+
+// SortByApplyOrder returns a copy of objs sorted in the order in which they should be applied to a cluster.
+func SortByApplyOrder(objs K8sObjects) K8sObjects {
+	ret := make(K8sObjects, len(objs))
+	copy(ret, objs)
+	ret.Sort(ApplyOrderScore)
+	return ret
+}
+
+// SortByDeleteOrder returns a copy of objs sorted in the order in which they should be deleted from a cluster, which
+// is the reverse of the apply order.
+func SortByDeleteOrder(objs K8sObjects) K8sObjects {
+	return Reverse(SortByApplyOrder(objs))
+}
+
+// Reverse returns a copy of objs in reverse order.
+func Reverse(objs K8sObjects) K8sObjects {
+	ret := make(K8sObjects, len(objs))
+	for i, o := range objs {
+		ret[len(objs)-1-i] = o
+	}
+	return ret
+}
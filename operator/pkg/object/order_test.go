@@ -0,0 +1,140 @@
+// Copyright 2020 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import (
+	"testing"
+)
+
+const orderTestManifest = `
+apiVersion: admissionregistration.k8s.io/v1beta1
+kind: MutatingWebhookConfiguration
+metadata:
+  name: istio-sidecar-injector
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: istiod
+  namespace: istio-system
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: istiod
+  namespace: istio-system
+---
+apiVersion: networking.istio.io/v1alpha3
+kind: Gateway
+metadata:
+  name: ingressgateway
+  namespace: istio-system
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: istio
+  namespace: istio-system
+---
+apiVersion: rbac.authorization.k8s.io/v1
+kind: ClusterRoleBinding
+metadata:
+  name: istiod
+---
+apiVersion: rbac.authorization.k8s.io/v1
+kind: ClusterRole
+metadata:
+  name: istiod
+---
+apiVersion: apiextensions.k8s.io/v1beta1
+kind: CustomResourceDefinition
+metadata:
+  name: gateways.networking.istio.io
+---
+apiVersion: v1
+kind: Namespace
+metadata:
+  name: istio-system
+`
+
+func kindIndexes(objs K8sObjects) map[string]int {
+	ret := make(map[string]int)
+	for i, o := range objs {
+		ret[o.Kind] = i
+	}
+	return ret
+}
+
+func TestSortByApplyOrder(t *testing.T) {
+	objs, err := ParseK8sObjectsFromYAMLManifest(orderTestManifest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := kindIndexes(SortByApplyOrder(objs))
+
+	tests := []struct {
+		desc   string
+		before string
+		after  string
+	}{
+		{"NamespaceBeforeCRD", "Namespace", "CustomResourceDefinition"},
+		{"NamespaceBeforeNamespacedResources", "Namespace", "ConfigMap"},
+		{"CRDBeforeCR", "CustomResourceDefinition", "Gateway"},
+		{"RoleBeforeBinding", "ClusterRole", "ClusterRoleBinding"},
+		{"RBACBeforeConfigMap", "ClusterRoleBinding", "ConfigMap"},
+		{"ConfigMapBeforeDeployment", "ConfigMap", "Deployment"},
+		{"DeploymentBeforeService", "Deployment", "Service"},
+		{"WebhookLast", "Service", "MutatingWebhookConfiguration"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			if got[tt.before] >= got[tt.after] {
+				t.Errorf("%s at position %d, want before %s at position %d", tt.before, got[tt.before], tt.after, got[tt.after])
+			}
+		})
+	}
+}
+
+func TestSortByApplyOrderDoesNotModifyInput(t *testing.T) {
+	objs, err := ParseK8sObjectsFromYAMLManifest(orderTestManifest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	first := objs[0]
+	SortByApplyOrder(objs)
+	if objs[0] != first {
+		t.Errorf("input was modified, got first object %s, want %s", objs[0].Hash(), first.Hash())
+	}
+}
+
+func TestSortByDeleteOrder(t *testing.T) {
+	objs, err := ParseK8sObjectsFromYAMLManifest(orderTestManifest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	apply := SortByApplyOrder(objs)
+	del := SortByDeleteOrder(objs)
+	if len(apply) != len(del) {
+		t.Fatalf("got %d objects, want %d", len(del), len(apply))
+	}
+	for i := range apply {
+		if apply[i] != del[len(del)-1-i] {
+			t.Errorf("position %d: got %s, want %s", i, del[len(del)-1-i].Hash(), apply[i].Hash())
+		}
+	}
+	if del[len(del)-1].Kind != "Namespace" {
+		t.Errorf("got last deleted kind %s, want Namespace", del[len(del)-1].Kind)
+	}
+}
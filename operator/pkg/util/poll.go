@@ -0,0 +1,84 @@
+// Copyright 2020 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"time"
+)
+
+const (
+	// maxPollInterval caps the backoff between two calls of a PollUntil condition.
+	maxPollInterval = 10 * time.Second
+	// pollJitter is the maximum fraction of the interval randomly added to each wait.
+	pollJitter = 0.1
+)
+
+// ErrPollTimeout is returned by PollUntil when the condition is not met before the timeout.
+var ErrPollTimeout = errors.New("timed out waiting for the condition")
+
+// PollUntil calls condition until it returns true, returns an error, or the timeout expires or ctx is done.
+// The condition is called immediately, then after waiting interval, and the wait doubles with some jitter after
+// each call, up to maxPollInterval. An error returned by the condition stops polling and is returned as is.
+// A timeout of zero or less means there is no timeout other than the deadline of ctx. The interval must be positive.
+func PollUntil(ctx context.Context, interval, timeout time.Duration, condition func() (bool, error)) error {
+	return poll(ctx, interval, maxPollInterval, timeout, condition)
+}
+
+// PollAtInterval is like PollUntil, except that it waits interval, with some jitter, between all calls of condition.
+func PollAtInterval(ctx context.Context, interval, timeout time.Duration, condition func() (bool, error)) error {
+	return poll(ctx, interval, interval, timeout, condition)
+}
+
+// poll calls condition as described by PollUntil, doubling the wait after each call up to maxInterval.
+func poll(ctx context.Context, interval, maxInterval, timeout time.Duration, condition func() (bool, error)) error {
+	if interval <= 0 {
+		return fmt.Errorf("poll interval must be positive, got %v", interval)
+	}
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	wait := interval
+	for {
+		done, err := condition()
+		if err != nil {
+			return err
+		}
+		if done {
+			return nil
+		}
+
+		t := time.NewTimer(wait + time.Duration(rand.Float64()*pollJitter*float64(wait)))
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+				return ErrPollTimeout
+			}
+			return ctx.Err()
+		case <-t.C:
+		}
+
+		if wait *= 2; wait > maxInterval {
+			wait = maxInterval
+		}
+	}
+}
@@ -0,0 +1,115 @@
+// Copyright 2020 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestPollUntil(t *testing.T) {
+	errCondition := errors.New("condition failed")
+	tests := []struct {
+		desc      string
+		condition func(calls int) (bool, error)
+		timeout   time.Duration
+		wantCalls int
+		wantErr   error
+	}{
+		{
+			desc:      "ImmediateSuccess",
+			condition: func(int) (bool, error) { return true, nil },
+			timeout:   time.Second,
+			wantCalls: 1,
+		},
+		{
+			desc:      "EventualSuccess",
+			condition: func(calls int) (bool, error) { return calls == 3, nil },
+			timeout:   time.Second,
+			wantCalls: 3,
+		},
+		{
+			desc:      "ConditionError",
+			condition: func(calls int) (bool, error) { return false, errCondition },
+			timeout:   time.Second,
+			wantCalls: 1,
+			wantErr:   errCondition,
+		},
+		{
+			desc:      "Timeout",
+			condition: func(int) (bool, error) { return false, nil },
+			timeout:   50 * time.Millisecond,
+			wantErr:   ErrPollTimeout,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			calls := 0
+			err := PollUntil(context.Background(), time.Millisecond, tt.timeout, func() (bool, error) {
+				calls++
+				return tt.condition(calls)
+			})
+			if err != tt.wantErr {
+				t.Errorf("got error %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantCalls != 0 && calls != tt.wantCalls {
+				t.Errorf("got %d calls, want %d", calls, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestPollUntilCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err := PollUntil(ctx, time.Millisecond, time.Second, func() (bool, error) { return false, nil })
+	if err != context.Canceled {
+		t.Errorf("got error %v, want %v", err, context.Canceled)
+	}
+}
+
+func TestPollUntilInvalidInterval(t *testing.T) {
+	for _, interval := range []time.Duration{0, -time.Second} {
+		calls := 0
+		err := PollUntil(context.Background(), interval, time.Second, func() (bool, error) {
+			calls++
+			return false, nil
+		})
+		if err == nil {
+			t.Errorf("interval %v: got no error, want an error", interval)
+		}
+		if calls != 0 {
+			t.Errorf("interval %v: got %d calls, want none", interval, calls)
+		}
+	}
+}
+
+func TestPollAtInterval(t *testing.T) {
+	calls := 0
+	start := time.Now()
+	err := PollAtInterval(context.Background(), 10*time.Millisecond, time.Second, func() (bool, error) {
+		calls++
+		return calls == 5, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// With backoff, the four waits would take at least 10+20+40+80ms.
+	if elapsed := time.Since(start); elapsed >= 150*time.Millisecond {
+		t.Errorf("got %v for 5 calls, want the interval not to back off", elapsed)
+	}
+}
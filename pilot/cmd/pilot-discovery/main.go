@@ -167,6 +167,10 @@ func init() {
 		"HTTP address to use for pilot's self-monitoring information")
 	discoveryCmd.PersistentFlags().BoolVar(&serverArgs.DiscoveryOptions.EnableProfiling, "profile", true,
 		"Enable profiling via web interface host:port/debug/pprof")
+	discoveryCmd.PersistentFlags().IntVar(&serverArgs.DiscoveryOptions.MaxListeners, "maxListeners", 0,
+		"Number of listeners of a proxy above which a warning is logged, 0 for no limit")
+	discoveryCmd.PersistentFlags().BoolVar(&serverArgs.DiscoveryOptions.TruncateListeners, "truncateListeners", false,
+		"Drop the listeners of a proxy above maxListeners instead of only logging a warning")
 
 	// Attach the Istio logging options to the command.
 	loggingOptions.AttachCobraFlags(rootCmd)
@@ -110,6 +110,12 @@ type DiscoveryServiceOptions struct {
 	MonitoringAddr string
 
 	EnableProfiling bool
+
+	// MaxListeners is the number of listeners of a proxy above which a warning is logged. Zero means no limit.
+	MaxListeners int
+
+	// TruncateListeners drops the listeners of a proxy above MaxListeners instead of only logging a warning.
+	TruncateListeners bool
 }
 
 type InjectionOptions struct {
@@ -175,6 +175,9 @@ func NewServer(args *PilotArgs) (*Server, error) {
 		mux:            http.NewServeMux(),
 	}
 
+	s.EnvoyXdsServer.MaxListeners = args.DiscoveryOptions.MaxListeners
+	s.EnvoyXdsServer.TruncateListeners = args.DiscoveryOptions.TruncateListeners
+
 	log.Infof("Primary Cluster name: %s", s.clusterID)
 
 	prometheus.EnableHandlingTimeHistogram()
@@ -62,6 +62,28 @@ var (
 	// For larger clusters it can increase memory use and GC - useful for small tests.
 	DebugConfigs = env.RegisterBoolVar("PILOT_DEBUG_ADSZ_CONFIG", false, "").Get()
 
+	// EnableDebugTopology controls serving the mesh topology on /debug/topology.
+	EnableDebugTopology = env.RegisterBoolVar(
+		"PILOT_ENABLE_DEBUG_TOPOLOGY",
+		false,
+		"If enabled, Pilot serves the services, instances and routing config of the mesh as JSON on /debug/topology.",
+	).Get()
+
+	// EnableDebugMeshConfig controls serving the mesh config in use on /debug/meshconfig.
+	EnableDebugMeshConfig = env.RegisterBoolVar(
+		"PILOT_ENABLE_DEBUG_MESHCONFIG",
+		false,
+		"If enabled, Pilot serves the mesh config it is currently using on /debug/meshconfig.",
+	).Get()
+
+	// DebugLastPushes controls recording the last listener pushes of each proxy for /debug/lastpush.
+	DebugLastPushes = env.RegisterIntVar(
+		"PILOT_DEBUG_LAST_PUSHES",
+		0,
+		"If positive, Pilot keeps this many of the most recent listener pushes of each connected proxy and serves "+
+			"them as JSON on /debug/lastpush.",
+	).Get()
+
 	// FilterGatewayClusterConfig controls if a subset of clusters(only those required) should be pushed to gateways
 	FilterGatewayClusterConfig = env.RegisterBoolVar("PILOT_FILTER_GATEWAY_CLUSTER_CONFIG", false, "").Get()
 
@@ -102,6 +124,15 @@ var (
 		"Enables the use of HTTP 1.0 in the outbound HTTP listeners, to support legacy applications.",
 	).Get()
 
+	// HTTPIdleTimeout is the default idle timeout of the HTTP connection managers of all proxies served by this
+	// Pilot. The IDLE_TIMEOUT node metadata of a proxy overrides it, and an IDLE_TIMEOUT of 0s disables it.
+	HTTPIdleTimeout = env.RegisterDurationVar(
+		"PILOT_HTTP_IDLE_TIMEOUT",
+		0,
+		"The default idle timeout of downstream HTTP connections, after which connections without active requests "+
+			"are closed. The IDLE_TIMEOUT proxy metadata overrides it. If neither is set, Envoy's default is used.",
+	).Get()
+
 	initialFetchTimeoutVar = env.RegisterDurationVar(
 		"PILOT_INITIAL_FETCH_TIMEOUT",
 		0,
@@ -360,6 +360,11 @@ type NodeMetadata struct {
 	// Alpha in 1.1, based on feedback may be turned into an API or change. Set to "1" to enable.
 	HTTP10 string `json:"HTTP10,omitempty"`
 
+	// ProxyProtocol indicates the gateway is behind a load balancer which sends the PROXY protocol header on each
+	// connection. It adds the PROXY protocol listener filter to all gateway listeners, so that the original client
+	// address is preserved. Set to "true" to enable.
+	ProxyProtocol StringBool `json:"PROXY_PROTOCOL,omitempty"`
+
 	// Contains a copy of the raw metadata. This is needed to lookup arbitrary values.
 	// If a value is known ahead of time it should be added to the struct rather than reading from here,
 	Raw map[string]interface{} `json:"-"`
@@ -26,6 +26,9 @@ import (
 	"istio.io/pkg/monitoring"
 )
 
+// HTTP3Annotation on a Gateway set to "true" serves its HTTPS servers terminating TLS over HTTP/3 too.
+const HTTP3Annotation = "networking.istio.io/http3"
+
 // MergedGateway describes a set of gateways for a workload merged into a single logical gateway.
 //
 // TODO: do we need a `func (m *MergedGateway) MergeInto(gateway *networking.Gateway)`?
@@ -46,6 +49,9 @@ type MergedGateway struct {
 	// Inverse of ServersByRouteName. Returning this as part of merge result allows to keep route name generation logic
 	// encapsulated within the model and, as a side effect, to avoid generating route names twice.
 	RouteNamesByServer map[*networking.Server]string
+
+	// set of servers of gateways with the HTTP3Annotation
+	HTTP3Servers map[*networking.Server]bool
 }
 
 var (
@@ -79,6 +85,7 @@ func MergeGateways(gateways ...Config) *MergedGateway {
 	serversByRouteName := make(map[string][]*networking.Server)
 	routeNamesByServer := make(map[*networking.Server]string)
 	gatewayNameForServer := make(map[*networking.Server]string)
+	http3Servers := make(map[*networking.Server]bool)
 	tlsHostsByPort := map[uint32]map[string]struct{}{} // port -> host -> exists
 
 	log.Debugf("MergeGateways: merging %d gateways", len(gateways))
@@ -91,6 +98,9 @@ func MergeGateways(gateways ...Config) *MergedGateway {
 		for _, s := range gatewayCfg.Servers {
 			sanitizeServerHostNamespace(s, gatewayConfig.Namespace)
 			gatewayNameForServer[s] = gatewayName
+			if gatewayConfig.Annotations[HTTP3Annotation] == "true" {
+				http3Servers[s] = true
+			}
 			log.Debugf("MergeGateways: gateway %q processing server %v", gatewayName, s.Hosts)
 			p := protocol.Parse(s.Port.Protocol)
 
@@ -199,6 +209,7 @@ func MergeGateways(gateways ...Config) *MergedGateway {
 		GatewayNameForServer: gatewayNameForServer,
 		ServersByRouteName:   serversByRouteName,
 		RouteNamesByServer:   routeNamesByServer,
+		HTTP3Servers:         http3Servers,
 	}
 }
 
@@ -80,13 +80,17 @@ func (configgen *ConfigGeneratorImpl) buildGatewayListeners(
 		// on a given port, we can either have plain text HTTP servers or
 		// HTTPS/TLS servers with SNI. We cannot have a mix of http and https server on same port.
 		opts := buildListenerOpts{
-			push:       push,
-			proxy:      node,
-			bind:       actualWildcard,
-			port:       int(portNumber),
-			bindToPort: true,
+			push:          push,
+			proxy:         node,
+			bind:          actualWildcard,
+			port:          int(portNumber),
+			bindToPort:    true,
+			proxyProtocol: bool(node.Metadata.ProxyProtocol),
 		}
 
+		// filter chains of the QUIC listener for HTTPS servers on this port of gateways declaring HTTP/3
+		var quicFilterChainOpts []*filterChainOpts
+
 		p := protocol.Parse(servers[0].Port.Protocol)
 		listenerProtocol := plugin.ModelProtocolToListenerProtocol(node, p, core.TrafficDirection_OUTBOUND)
 		if p.IsHTTP() {
@@ -108,6 +112,12 @@ func (configgen *ConfigGeneratorImpl) buildGatewayListeners(
 					// This is a HTTPS server, where we are doing TLS termination. Build a http connection manager with TLS context
 					routeName := mergedGateway.RouteNamesByServer[server]
 					filterChainOpts = append(filterChainOpts, configgen.createGatewayHTTPFilterChainOpts(node, server, routeName, push.Mesh.SdsUdsPath))
+					if mergedGateway.HTTP3Servers[server] {
+						// The gateway declares HTTP/3, so the server is also served on a QUIC listener
+						quicOpts := configgen.createGatewayHTTPFilterChainOpts(node, server, routeName, push.Mesh.SdsUdsPath)
+						quicOpts.httpOpts.http3 = true
+						quicFilterChainOpts = append(quicFilterChainOpts, quicOpts)
+					}
 				} else {
 					// passthrough or tcp, yields multiple filter chains
 					filterChainOpts = append(filterChainOpts, configgen.createGatewayTCPFilterChainOpts(node, push,
@@ -117,31 +127,6 @@ func (configgen *ConfigGeneratorImpl) buildGatewayListeners(
 			opts.filterChainOpts = filterChainOpts
 		}
 
-		l := buildListener(opts)
-		l.TrafficDirection = core.TrafficDirection_OUTBOUND
-
-		mutable := &plugin.MutableObjects{
-			Listener: l,
-			// Note: buildListener creates filter chains but does not populate the filters in the chain; that's what
-			// this is for.
-			FilterChains: make([]plugin.FilterChain, len(l.FilterChains)),
-		}
-
-		// Begin shady logic
-		// buildListener builds an empty array of filters in the listener struct
-		// mutable object above has a FilterChains field that has same number of empty structs (matching number of
-		// filter chains). All plugins iterate over this array, and fill up the HTTP or TCP part of the
-		// plugin.FilterChain struct.
-		// TODO: need a cleaner way of communicating this info
-		for i := range mutable.FilterChains {
-			if opts.filterChainOpts[i].httpOpts != nil {
-				mutable.FilterChains[i].ListenerProtocol = plugin.ListenerProtocolHTTP
-			} else {
-				mutable.FilterChains[i].ListenerProtocol = plugin.ListenerProtocolTCP
-			}
-		}
-		// end shady logic
-
 		pluginParams := &plugin.InputParams{
 			ListenerProtocol:           listenerProtocol,
 			DeprecatedListenerCategory: networking.EnvoyFilter_DeprecatedListenerMatch_GATEWAY,
@@ -154,28 +139,22 @@ func (configgen *ConfigGeneratorImpl) buildGatewayListeners(
 				Protocol: p,
 			},
 		}
-		for _, p := range configgen.Plugins {
-			if err := p.OnOutboundListener(pluginParams, mutable); err != nil {
-				log.Warna("buildGatewayListeners: failed to build listener for gateway: ", err.Error())
-			}
-		}
-
-		// Filters are serialized one time into an opaque struct once we have the complete list.
-		if err := buildCompleteFilterChain(pluginParams, mutable, opts); err != nil {
-			errs = multierror.Append(errs, fmt.Errorf("gateway omitting listener %q due to: %v", mutable.Listener.Name, err.Error()))
-			continue
-		}
-
-		if err := mutable.Listener.Validate(); err != nil {
-			errs = multierror.Append(errs, fmt.Errorf("gateway listener %s validation failed: %v", mutable.Listener.Name, err.Error()))
+		l, err := configgen.buildGatewayListener(opts, pluginParams)
+		if err != nil {
+			errs = multierror.Append(errs, err)
 			continue
 		}
+		listeners = append(listeners, l)
 
-		if log.DebugEnabled() {
-			log.Debugf("buildGatewayListeners: constructed listener with %d filter chains:\n%v",
-				len(mutable.Listener.FilterChains), mutable.Listener)
+		if len(quicFilterChainOpts) > 0 {
+			opts.filterChainOpts = quicFilterChainOpts
+			opts.quic = true
+			if l, err = configgen.buildGatewayListener(opts, pluginParams); err != nil {
+				errs = multierror.Append(errs, err)
+				continue
+			}
+			listeners = append(listeners, l)
 		}
-		listeners = append(listeners, mutable.Listener)
 	}
 	// We'll try to return any listeners we successfully marshaled; if we have none, we'll emit the error we built up
 	err := errs.ErrorOrNil()
@@ -202,6 +181,56 @@ func (configgen *ConfigGeneratorImpl) buildGatewayListeners(
 	return builder
 }
 
+// buildGatewayListener builds the listener described by opts and completes its filter chains with the plugins.
+func (configgen *ConfigGeneratorImpl) buildGatewayListener(opts buildListenerOpts,
+	pluginParams *plugin.InputParams) (*xdsapi.Listener, error) {
+	l := buildListener(opts)
+	l.TrafficDirection = core.TrafficDirection_OUTBOUND
+
+	mutable := &plugin.MutableObjects{
+		Listener: l,
+		// Note: buildListener creates filter chains but does not populate the filters in the chain; that's what
+		// this is for.
+		FilterChains: make([]plugin.FilterChain, len(l.FilterChains)),
+	}
+
+	// Begin shady logic
+	// buildListener builds an empty array of filters in the listener struct
+	// mutable object above has a FilterChains field that has same number of empty structs (matching number of
+	// filter chains). All plugins iterate over this array, and fill up the HTTP or TCP part of the
+	// plugin.FilterChain struct.
+	// TODO: need a cleaner way of communicating this info
+	for i := range mutable.FilterChains {
+		if opts.filterChainOpts[i].httpOpts != nil {
+			mutable.FilterChains[i].ListenerProtocol = plugin.ListenerProtocolHTTP
+		} else {
+			mutable.FilterChains[i].ListenerProtocol = plugin.ListenerProtocolTCP
+		}
+	}
+	// end shady logic
+
+	for _, p := range configgen.Plugins {
+		if err := p.OnOutboundListener(pluginParams, mutable); err != nil {
+			log.Warna("buildGatewayListeners: failed to build listener for gateway: ", err.Error())
+		}
+	}
+
+	// Filters are serialized one time into an opaque struct once we have the complete list.
+	if err := buildCompleteFilterChain(pluginParams, mutable, opts); err != nil {
+		return nil, fmt.Errorf("gateway omitting listener %q due to: %v", mutable.Listener.Name, err.Error())
+	}
+
+	if err := mutable.Listener.Validate(); err != nil {
+		return nil, fmt.Errorf("gateway listener %s validation failed: %v", mutable.Listener.Name, err.Error())
+	}
+
+	if log.DebugEnabled() {
+		log.Debugf("buildGatewayListeners: constructed listener with %d filter chains:\n%v",
+			len(mutable.Listener.FilterChains), mutable.Listener)
+	}
+	return mutable.Listener, nil
+}
+
 func (configgen *ConfigGeneratorImpl) buildGatewayHTTPRouteConfig(node *model.Proxy, push *model.PushContext,
 	routeName string) *xdsapi.RouteConfiguration {
 
@@ -311,6 +340,20 @@ func (configgen *ConfigGeneratorImpl) buildGatewayHTTPRouteConfig(node *model.Pr
 		ValidateClusters: proto.BoolFalse,
 	}
 
+	for _, server := range servers {
+		if merged.HTTP3Servers[server] && gateway.IsTLSServer(server) && gateway.IsHTTPServer(server) {
+			// The server is also served over HTTP/3 on a QUIC listener, which clients discover with alt-svc
+			routeCfg.ResponseHeadersToAdd = []*core.HeaderValueOption{{
+				Header: &core.HeaderValue{
+					Key:   "alt-svc",
+					Value: fmt.Sprintf(`h3=":%d"; ma=86400`, port),
+				},
+				Append: proto.BoolFalse,
+			}}
+			break
+		}
+	}
+
 	in := &plugin.InputParams{
 		ListenerProtocol: plugin.ListenerProtocolHTTP,
 		ListenerCategory: networking.EnvoyFilter_GATEWAY,
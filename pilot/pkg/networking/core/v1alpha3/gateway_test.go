@@ -19,9 +19,11 @@ import (
 	"sort"
 	"testing"
 
+	xdsapi "github.com/envoyproxy/go-control-plane/envoy/api/v2"
 	auth "github.com/envoyproxy/go-control-plane/envoy/api/v2/auth"
 	core "github.com/envoyproxy/go-control-plane/envoy/api/v2/core"
 	http_conn "github.com/envoyproxy/go-control-plane/envoy/config/filter/network/http_connection_manager/v2"
+	"github.com/envoyproxy/go-control-plane/pkg/wellknown"
 
 	networking "istio.io/api/networking/v1alpha3"
 
@@ -1024,3 +1026,280 @@ func buildEnv(t *testing.T, gateways []pilot_model.Config, virtualServices []pil
 	}
 	return env
 }
+
+func TestBuildGatewayListenersWildcardSNI(t *testing.T) {
+	cases := []struct {
+		name                string
+		hosts               []string
+		expectedServerNames []string
+	}{
+		{
+			name:                "wildcard hosts keep the wildcard form",
+			hosts:               []string{"*.example.com", "ns/*.bar.example.com"},
+			expectedServerNames: []string{"*.bar.example.com", "*.example.com"},
+		},
+		{
+			name:                "mixed wildcard and exact hosts",
+			hosts:               []string{"foo.example.com", "*.example.com"},
+			expectedServerNames: []string{"*.example.com", "foo.example.com"},
+		},
+		{
+			name:                "full wildcard host matches any SNI",
+			hosts:               []string{"*", "*.example.com"},
+			expectedServerNames: nil,
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			gateway := &networking.Gateway{
+				Servers: []*networking.Server{
+					{
+						Port:  &networking.Port{Name: "https", Number: 443, Protocol: "HTTPS"},
+						Hosts: tt.hosts,
+						Tls: &networking.Server_TLSOptions{
+							Mode:              networking.Server_TLSOptions_SIMPLE,
+							ServerCertificate: "/etc/cert/cert.pem",
+							PrivateKey:        "/etc/cert/key.pem",
+						},
+					},
+				},
+			}
+			configgen := NewConfigGenerator([]plugin.Plugin{&fakePlugin{}})
+			env := buildEnv(t, []pilot_model.Config{{Spec: gateway}}, []pilot_model.Config{})
+			proxy14Gateway.SetGatewaysForProxy(env.PushContext)
+			proxy14Gateway.ServiceInstances = nil
+			builder := configgen.buildGatewayListeners(&proxy14Gateway, env.PushContext, &ListenerBuilder{})
+			if len(builder.gatewayListeners) != 1 {
+				t.Fatalf("expected 1 listener, got %d", len(builder.gatewayListeners))
+			}
+			l := builder.gatewayListeners[0]
+			if len(l.FilterChains) != 1 {
+				t.Fatalf("expected 1 filter chain, got %d", len(l.FilterChains))
+			}
+			var serverNames []string
+			if match := l.FilterChains[0].FilterChainMatch; match != nil {
+				serverNames = match.ServerNames
+			}
+			if !reflect.DeepEqual(serverNames, tt.expectedServerNames) {
+				t.Errorf("expected server names %v, got %v", tt.expectedServerNames, serverNames)
+			}
+		})
+	}
+}
+
+func TestBuildGatewayListenersProxyProtocol(t *testing.T) {
+	gateway := &networking.Gateway{
+		Servers: []*networking.Server{
+			{
+				Port:  &networking.Port{Name: "https", Number: 443, Protocol: "HTTPS"},
+				Hosts: []string{"example.com"},
+				Tls: &networking.Server_TLSOptions{
+					Mode:              networking.Server_TLSOptions_SIMPLE,
+					ServerCertificate: "/etc/cert/cert.pem",
+					PrivateKey:        "/etc/cert/key.pem",
+				},
+			},
+		},
+	}
+
+	cases := []struct {
+		name                    string
+		proxyProtocol           bool
+		expectedListenerFilters []string
+	}{
+		{
+			name:                    "disabled by default",
+			expectedListenerFilters: []string{wellknown.TlsInspector},
+		},
+		{
+			name:                    "enabled",
+			proxyProtocol:           true,
+			expectedListenerFilters: []string{wellknown.ProxyProtocol, wellknown.TlsInspector},
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			configgen := NewConfigGenerator([]plugin.Plugin{&fakePlugin{}})
+			env := buildEnv(t, []pilot_model.Config{{Spec: gateway}}, []pilot_model.Config{})
+			proxy := proxy14Gateway
+			metadata := *proxy14Gateway.Metadata
+			metadata.ProxyProtocol = pilot_model.StringBool(tt.proxyProtocol)
+			proxy.Metadata = &metadata
+			proxy.ServiceInstances = nil
+			proxy.SetGatewaysForProxy(env.PushContext)
+			builder := configgen.buildGatewayListeners(&proxy, env.PushContext, &ListenerBuilder{})
+			if len(builder.gatewayListeners) != 1 {
+				t.Fatalf("expected 1 listener, got %d", len(builder.gatewayListeners))
+			}
+			var listenerFilters []string
+			for _, lf := range builder.gatewayListeners[0].ListenerFilters {
+				listenerFilters = append(listenerFilters, lf.Name)
+			}
+			if !reflect.DeepEqual(listenerFilters, tt.expectedListenerFilters) {
+				t.Errorf("expected listener filters %v, got %v", tt.expectedListenerFilters, listenerFilters)
+			}
+		})
+	}
+}
+
+func TestBuildGatewayListenersQUIC(t *testing.T) {
+	gateway := pilot_model.Config{
+		ConfigMeta: pilot_model.ConfigMeta{
+			Name:      "gateway",
+			Namespace: "default",
+		},
+		Spec: &networking.Gateway{
+			Servers: []*networking.Server{
+				{
+					Port:  &networking.Port{Name: "http", Number: 80, Protocol: "HTTP"},
+					Hosts: []string{"example.com"},
+				},
+				{
+					Port:  &networking.Port{Name: "https", Number: 443, Protocol: "HTTPS"},
+					Hosts: []string{"example.com"},
+					Tls: &networking.Server_TLSOptions{
+						Mode:              networking.Server_TLSOptions_SIMPLE,
+						ServerCertificate: "/etc/cert/cert.pem",
+						PrivateKey:        "/etc/cert/key.pem",
+					},
+				},
+			},
+		},
+	}
+
+	cases := []struct {
+		name              string
+		annotations       map[string]string
+		quic              bool
+		expectedListeners []string
+	}{
+		{
+			name:              "disabled by default",
+			expectedListeners: []string{"0.0.0.0_443", "0.0.0.0_80"},
+		},
+		{
+			name:              "disabled by annotation",
+			annotations:       map[string]string{pilot_model.HTTP3Annotation: "false"},
+			expectedListeners: []string{"0.0.0.0_443", "0.0.0.0_80"},
+		},
+		{
+			name:              "enabled by annotation",
+			annotations:       map[string]string{pilot_model.HTTP3Annotation: "true"},
+			quic:              true,
+			expectedListeners: []string{"0.0.0.0_443", "0.0.0.0_80", "udp_0.0.0.0_443"},
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			gateway := gateway
+			gateway.Annotations = tt.annotations
+			configgen := NewConfigGenerator([]plugin.Plugin{&fakePlugin{}})
+			env := buildEnv(t, []pilot_model.Config{gateway}, []pilot_model.Config{})
+			proxy := proxy14Gateway
+			proxy.ServiceInstances = nil
+			proxy.SetGatewaysForProxy(env.PushContext)
+			builder := configgen.buildGatewayListeners(&proxy, env.PushContext, &ListenerBuilder{})
+
+			var listeners []string
+			var quicListener *xdsapi.Listener
+			for _, l := range builder.gatewayListeners {
+				listeners = append(listeners, l.Name)
+				if l.Name == "udp_0.0.0.0_443" {
+					quicListener = l
+				}
+			}
+			sort.Strings(listeners)
+			if !reflect.DeepEqual(listeners, tt.expectedListeners) {
+				t.Fatalf("expected listeners %v, got %v", tt.expectedListeners, listeners)
+			}
+
+			var httpsRouteName string
+			for server, routeName := range proxy.MergedGateway.RouteNamesByServer {
+				if server.Port.Number == 443 {
+					httpsRouteName = routeName
+				}
+			}
+			routeCfg := configgen.buildGatewayHTTPRouteConfig(&proxy, env.PushContext, httpsRouteName)
+			var altSvc []string
+			for _, h := range routeCfg.ResponseHeadersToAdd {
+				if h.Header.Key == "alt-svc" {
+					altSvc = append(altSvc, h.Header.Value)
+				}
+			}
+
+			if !tt.quic {
+				if len(altSvc) != 0 {
+					t.Errorf("expected no alt-svc header, got %v", altSvc)
+				}
+				return
+			}
+			if want := []string{`h3=":443"; ma=86400`}; !reflect.DeepEqual(altSvc, want) {
+				t.Errorf("expected alt-svc header %v, got %v", want, altSvc)
+			}
+
+			if quicListener.Address.GetSocketAddress().Protocol != core.SocketAddress_UDP {
+				t.Errorf("expected a UDP address, got %v", quicListener.Address)
+			}
+			if quicListener.UdpListenerConfig.GetUdpListenerName() != quicListenerName {
+				t.Errorf("expected UDP listener %s, got %v", quicListenerName, quicListener.UdpListenerConfig)
+			}
+			if len(quicListener.ListenerFilters) != 0 {
+				t.Errorf("expected no listener filters, got %v", quicListener.ListenerFilters)
+			}
+			if len(quicListener.FilterChains) != 1 {
+				t.Fatalf("expected 1 filter chain, got %d", len(quicListener.FilterChains))
+			}
+			fc := quicListener.FilterChains[0]
+			if fc.TransportSocket.GetName() != quicTransportSocketName {
+				t.Errorf("expected transport socket %s, got %v", quicTransportSocketName, fc.TransportSocket)
+			}
+			hcm := &http_conn.HttpConnectionManager{}
+			if err := getFilterConfig(fc.Filters[len(fc.Filters)-1], hcm); err != nil {
+				t.Fatal(err)
+			}
+			if hcm.CodecType != http_conn.HttpConnectionManager_HTTP3 {
+				t.Errorf("expected codec HTTP3, got %v", hcm.CodecType)
+			}
+		})
+	}
+}
+
+func TestBuildGatewayHTTPRouteConfigAltSvcNotFirstServer(t *testing.T) {
+	tlsOptions := &networking.Server_TLSOptions{
+		Mode:              networking.Server_TLSOptions_SIMPLE,
+		ServerCertificate: "/etc/cert/cert.pem",
+		PrivateKey:        "/etc/cert/key.pem",
+	}
+	first := &networking.Server{
+		Port:  &networking.Port{Name: "https", Number: 443, Protocol: "HTTPS"},
+		Hosts: []string{"a.example.com"},
+		Tls:   tlsOptions,
+	}
+	second := &networking.Server{
+		Port:  &networking.Port{Name: "https", Number: 443, Protocol: "HTTPS"},
+		Hosts: []string{"b.example.com"},
+		Tls:   tlsOptions,
+	}
+
+	configgen := NewConfigGenerator([]plugin.Plugin{&fakePlugin{}})
+	env := buildEnv(t, []pilot_model.Config{}, []pilot_model.Config{})
+	proxy := proxy14Gateway
+	proxy.MergedGateway = &pilot_model.MergedGateway{
+		ServersByRouteName: map[string][]*networking.Server{"route": {first, second}},
+		HTTP3Servers:       map[*networking.Server]bool{second: true},
+	}
+
+	routeCfg := configgen.buildGatewayHTTPRouteConfig(&proxy, env.PushContext, "route")
+	var altSvc []string
+	for _, h := range routeCfg.ResponseHeadersToAdd {
+		if h.Header.Key == "alt-svc" {
+			altSvc = append(altSvc, h.Header.Value)
+		}
+	}
+	if want := []string{`h3=":443"; ma=86400`}; !reflect.DeepEqual(altSvc, want) {
+		t.Errorf("expected alt-svc header %v, got %v", want, altSvc)
+	}
+}
@@ -150,6 +150,13 @@ const (
 	AlpnFilterName = "istio.alpn"
 
 	ThriftRLSDefaultTimeoutMS = 50
+
+	// quicListenerName is the UDP listener of Envoy terminating QUIC.
+	quicListenerName = "quiche_quic_listener"
+
+	// quicTransportSocketName is the transport socket of Envoy for QUIC downstream connections. It takes the same
+	// DownstreamTlsContext as the TLS transport socket.
+	quicTransportSocketName = "envoy.transport_sockets.quic"
 )
 
 type FilterChainMatchOptions struct {
@@ -1909,6 +1916,8 @@ type httpListenerOpts struct {
 	// should be added.
 	addGRPCWebFilter bool
 	useRemoteAddress bool
+	// http3 serves HTTP/3 instead of detecting HTTP/1.1 or HTTP/2, for QUIC listeners.
+	http3 bool
 }
 
 // thriftListenerOpts are options for a Thrift listener
@@ -1945,6 +1954,10 @@ type buildListenerOpts struct {
 	bindToPort        bool
 	skipUserFilters   bool
 	needHTTPInspector bool
+	// proxyProtocol adds the PROXY protocol listener filter, for listeners behind a load balancer sending it.
+	proxyProtocol bool
+	// quic builds a UDP listener terminating QUIC instead of a TCP listener.
+	quic bool
 }
 
 func buildHTTPConnectionManager(pluginParams *plugin.InputParams, httpOpts *httpListenerOpts,
@@ -2009,6 +2022,9 @@ func buildHTTPConnectionManager(pluginParams *plugin.InputParams, httpOpts *http
 
 	connectionManager := httpOpts.connectionManager
 	connectionManager.CodecType = http_conn.HttpConnectionManager_AUTO
+	if httpOpts.http3 {
+		connectionManager.CodecType = http_conn.HttpConnectionManager_HTTP3
+	}
 	connectionManager.AccessLog = []*accesslog.AccessLog{}
 	connectionManager.HttpFilters = filters
 	connectionManager.StatPrefix = httpOpts.statPrefix
@@ -2023,8 +2039,12 @@ func buildHTTPConnectionManager(pluginParams *plugin.InputParams, httpOpts *http
 	websocketUpgrade := &http_conn.HttpConnectionManager_UpgradeConfig{UpgradeType: "websocket"}
 	connectionManager.UpgradeConfigs = []*http_conn.HttpConnectionManager_UpgradeConfig{websocketUpgrade}
 
-	idleTimeout, err := time.ParseDuration(pluginParams.Node.Metadata.IdleTimeout)
-	if idleTimeout > 0 && err == nil {
+	// The proxy metadata overrides the PILOT_HTTP_IDLE_TIMEOUT default, and an explicit zero disables the timeout.
+	idleTimeout, setIdleTimeout := features.HTTPIdleTimeout, features.HTTPIdleTimeout > 0
+	if t, err := time.ParseDuration(pluginParams.Node.Metadata.IdleTimeout); err == nil && t >= 0 {
+		idleTimeout, setIdleTimeout = t, true
+	}
+	if setIdleTimeout {
 		if util.IsIstioVersionGE14(pluginParams.Node) {
 			connectionManager.CommonHttpProtocolOptions = &core.HttpProtocolOptions{
 				IdleTimeout: ptypes.DurationProto(idleTimeout),
@@ -2177,6 +2197,11 @@ func buildListener(opts buildListenerOpts) *xdsapi.Listener {
 		listenerFilters = append(listenerFilters, &listener.ListenerFilter{Name: wellknown.HttpInspector})
 	}
 
+	if opts.proxyProtocol {
+		listenerFiltersMap[wellknown.ProxyProtocol] = true
+		listenerFilters = append(listenerFilters, &listener.ListenerFilter{Name: wellknown.ProxyProtocol})
+	}
+
 	for _, chain := range opts.filterChainOpts {
 		for _, filter := range chain.listenerFilters {
 			if _, exist := listenerFiltersMap[filter.Name]; !exist {
@@ -2227,6 +2252,8 @@ func buildListener(opts buildListenerOpts) *xdsapi.Listener {
 		})
 	}
 
+	sortListenerFilters(listenerFilters)
+
 	var deprecatedV1 *xdsapi.Listener_DeprecatedV1
 	if !opts.bindToPort {
 		deprecatedV1 = &xdsapi.Listener_DeprecatedV1{
@@ -2252,9 +2279,35 @@ func buildListener(opts buildListenerOpts) *xdsapi.Listener {
 		}
 	}
 
+	if opts.quic {
+		convertToQUICListener(listener)
+	}
+
 	return listener
 }
 
+// convertToQUICListener turns a TCP listener built by buildListener into a UDP listener terminating QUIC with the same
+// filter chains and TLS contexts. Listener filters inspect the start of a TCP stream, so they are dropped.
+func convertToQUICListener(l *xdsapi.Listener) {
+	l.Name = "udp_" + l.Name
+	if address := l.Address.GetSocketAddress(); address != nil {
+		address.Protocol = core.SocketAddress_UDP
+	}
+	l.ListenerFilters = nil
+	l.ReusePort = true
+	l.UdpListenerConfig = &listener.UdpListenerConfig{
+		UdpListenerName: quicListenerName,
+		ConfigType: &listener.UdpListenerConfig_TypedConfig{
+			TypedConfig: util.MessageToAny(&listener.QuicProtocolOptions{}),
+		},
+	}
+	for _, chain := range l.FilterChains {
+		if chain.TransportSocket != nil {
+			chain.TransportSocket.Name = quicTransportSocketName
+		}
+	}
+}
+
 // appendListenerFallthroughRoute adds a filter that will match all traffic and direct to the
 // PassthroughCluster. This should be appended as the final filter or it will mask the others.
 // This allows external https traffic, even when port the port (usually 443) is in use by another service.
@@ -2617,9 +2670,36 @@ func appendListenerFilters(filters []*listener.ListenerFilter) []*listener.Liste
 			append(filters, &listener.ListenerFilter{Name: wellknown.HttpInspector})
 	}
 
+	sortListenerFilters(filters)
 	return filters
 }
 
+// listenerFilterOrder is the canonical position of the well known listener filters. Envoy runs listener filters
+// in order, so the PROXY protocol header must be consumed before anything reads the connection, the original
+// destination must be restored before any inspection, and the HTTP inspector must run after the TLS inspector so
+// that it does not inspect TLS traffic.
+var listenerFilterOrder = map[string]int{
+	wellknown.ProxyProtocol:       0,
+	wellknown.OriginalDestination: 1,
+	wellknown.TlsInspector:        2,
+	wellknown.HttpInspector:       3,
+}
+
+// sortListenerFilters orders filters in the canonical listener filter order, so that the same config always produces
+// the same listener regardless of the order in which filter chains contributed their filters. Filters without a
+// canonical position keep their relative order after the well known ones.
+func sortListenerFilters(filters []*listener.ListenerFilter) {
+	position := func(f *listener.ListenerFilter) int {
+		if p, ok := listenerFilterOrder[f.Name]; ok {
+			return p
+		}
+		return len(listenerFilterOrder)
+	}
+	sort.SliceStable(filters, func(i, j int) bool {
+		return position(filters[i]) < position(filters[j])
+	})
+}
+
 // nolint: interfacer
 func buildDownstreamTLSTransportSocket(tlsContext *auth.DownstreamTlsContext) *core.TransportSocket {
 	if tlsContext == nil {
@@ -175,6 +175,8 @@ func (builder *ListenerBuilder) aggregateVirtualInboundListener(needTLSForPassTh
 			})
 	}
 
+	sortListenerFilters(builder.virtualInboundListener.ListenerFilters)
+
 	timeout := features.InboundProtocolDetectionTimeout
 	builder.virtualInboundListener.ListenerFiltersTimeout = ptypes.DurationProto(timeout)
 	builder.virtualInboundListener.ContinueOnListenerFiltersTimeout = true
@@ -34,6 +34,7 @@ import (
 	"github.com/gogo/protobuf/types"
 	"github.com/golang/protobuf/proto"
 	"github.com/golang/protobuf/ptypes"
+	"github.com/golang/protobuf/ptypes/duration"
 	"github.com/golang/protobuf/ptypes/wrappers"
 	"github.com/google/go-cmp/cmp"
 
@@ -575,6 +576,39 @@ func TestOutboundListenerConfig_WithSidecar(t *testing.T) {
 	testOutboundListenerConfigWithSidecarWithUseRemoteAddress(t, services...)
 }
 
+func TestSidecarListenersBindToPort(t *testing.T) {
+	services := []*model.Service{
+		buildService("test1.com", wildcardIP, protocol.HTTP, tnow),
+		buildService("test2.com", "1.2.3.4", protocol.TCP, tnow),
+	}
+	listeners := buildAllListeners(&fakePlugin{}, nil, services...)
+	if len(listeners) == 0 {
+		t.Fatal("expected listeners, found none")
+	}
+
+	virtual := map[string]bool{}
+	for _, l := range listeners {
+		bindToPort := l.DeprecatedV1 == nil || l.DeprecatedV1.BindToPort == nil || l.DeprecatedV1.BindToPort.Value
+		switch l.Name {
+		case VirtualOutboundListenerName, VirtualInboundListenerName:
+			// Only the listeners receiving the iptables redirect bind to their port.
+			virtual[l.Name] = true
+			if !bindToPort {
+				t.Errorf("expected listener %s to bind to its port", l.Name)
+			}
+		default:
+			if bindToPort {
+				t.Errorf("expected redirected listener %s not to bind to its port", l.Name)
+			}
+		}
+	}
+	for _, name := range []string{VirtualOutboundListenerName, VirtualInboundListenerName} {
+		if !virtual[name] {
+			t.Errorf("expected listener %s, found none", name)
+		}
+	}
+}
+
 func TestOutboundTlsTrafficWithoutTimeout(t *testing.T) {
 	services := []*model.Service{
 		{
@@ -1432,6 +1466,70 @@ func testOutboundListenerConfigWithSidecarWithCaptureModeNone(t *testing.T, serv
 	}
 }
 
+func TestHTTPConnectionManagerIdleTimeout(t *testing.T) {
+	defer func(d time.Duration) { features.HTTPIdleTimeout = d }(features.HTTPIdleTimeout)
+
+	cases := []struct {
+		name            string
+		envIdleTimeout  time.Duration
+		nodeIdleTimeout string
+		expected        *duration.Duration
+	}{
+		{
+			name: "unset",
+		},
+		{
+			name:           "env default",
+			envIdleTimeout: 30 * time.Second,
+			expected:       ptypes.DurationProto(30 * time.Second),
+		},
+		{
+			name:            "proxy metadata",
+			nodeIdleTimeout: "10s",
+			expected:        ptypes.DurationProto(10 * time.Second),
+		},
+		{
+			name:            "proxy metadata overrides env default",
+			envIdleTimeout:  30 * time.Second,
+			nodeIdleTimeout: "10s",
+			expected:        ptypes.DurationProto(10 * time.Second),
+		},
+		{
+			name:            "zero proxy metadata disables the env default",
+			envIdleTimeout:  30 * time.Second,
+			nodeIdleTimeout: "0s",
+			expected:        ptypes.DurationProto(0),
+		},
+		{
+			name:            "invalid proxy metadata uses env default",
+			envIdleTimeout:  30 * time.Second,
+			nodeIdleTimeout: "ten seconds",
+			expected:        ptypes.DurationProto(30 * time.Second),
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			features.HTTPIdleTimeout = tt.envIdleTimeout
+			pluginParams := &plugin.InputParams{
+				Node: &model.Proxy{
+					Metadata:     &model.NodeMetadata{IdleTimeout: tt.nodeIdleTimeout},
+					IstioVersion: &model.IstioVersion{Major: 1, Minor: 5},
+				},
+				Push: &model.PushContext{Mesh: &meshconfig.MeshConfig{}},
+			}
+			cm := buildHTTPConnectionManager(pluginParams, &httpListenerOpts{}, nil)
+			var got *duration.Duration
+			if cm.CommonHttpProtocolOptions != nil {
+				got = cm.CommonHttpProtocolOptions.IdleTimeout
+			}
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("expected idle timeout %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
 func TestOutboundListenerAccessLogs(t *testing.T) {
 	t.Helper()
 	p := &fakePlugin{}
@@ -1449,6 +1547,53 @@ func TestOutboundListenerAccessLogs(t *testing.T) {
 	}
 }
 
+func TestSortListenerFilters(t *testing.T) {
+	filters := []*listener.ListenerFilter{
+		{Name: "custom-filter"},
+		{Name: xdsutil.HttpInspector},
+		{Name: xdsutil.TlsInspector},
+		{Name: xdsutil.OriginalDestination},
+	}
+	sortListenerFilters(filters)
+
+	want := []string{xdsutil.OriginalDestination, xdsutil.TlsInspector, xdsutil.HttpInspector, "custom-filter"}
+	for i, f := range filters {
+		if f.Name != want[i] {
+			t.Errorf("filter %d: got %s, want %s", i, f.Name, want[i])
+		}
+	}
+}
+
+func TestListenerFilterOrderIsDeterministic(t *testing.T) {
+	services := []*model.Service{
+		buildService("test1.com", wildcardIP, protocol.HTTP, tnow),
+		buildService("test2.com", wildcardIP, "unknown", tnow),
+		buildService("test3.com", wildcardIP, protocol.HTTPS, tnow),
+	}
+	marshalListenerFilters := func() map[string][]byte {
+		ret := make(map[string][]byte)
+		for _, l := range buildAllListeners(&fakePlugin{}, nil, services...) {
+			b, err := proto.Marshal(&xdsapi.Listener{ListenerFilters: l.ListenerFilters})
+			if err != nil {
+				t.Fatal(err)
+			}
+			ret[l.Name] = b
+		}
+		return ret
+	}
+
+	first := marshalListenerFilters()
+	second := marshalListenerFilters()
+	if len(first) == 0 {
+		t.Fatal("expected listeners to be built")
+	}
+	for name, b := range first {
+		if !reflect.DeepEqual(b, second[name]) {
+			t.Errorf("listener %s: listener filters differ between builds", name)
+		}
+	}
+}
+
 func TestHttpProxyListener(t *testing.T) {
 	p := &fakePlugin{}
 	configgen := NewConfigGenerator([]plugin.Plugin{p})
@@ -459,6 +459,29 @@ func TestBuildHTTPRoutes(t *testing.T) {
 		g.Expect(ok).NotTo(gomega.BeFalse())
 		g.Expect(redirectAction.Redirect.ResponseCode).To(gomega.Equal(envoyroute.RedirectAction_PERMANENT_REDIRECT))
 	})
+	t.Run("for virtual service with timeout and retries", func(t *testing.T) {
+		g := gomega.NewGomegaWithT(t)
+
+		routes, err := route.BuildHTTPRoutesForVirtualService(node, nil, virtualServiceWithTimeoutAndRetries,
+			serviceRegistry, 8080, gatewayNames)
+		g.Expect(err).NotTo(gomega.HaveOccurred())
+		g.Expect(len(routes)).To(gomega.Equal(2))
+
+		action := routes[0].GetRoute()
+		g.Expect(action.GetTimeout().GetSeconds()).To(gomega.Equal(int64(2)))
+		g.Expect(action.GetMaxGrpcTimeout().GetSeconds()).To(gomega.Equal(int64(2)))
+		g.Expect(action.GetRetryPolicy().GetNumRetries().GetValue()).To(gomega.Equal(uint32(3)))
+		g.Expect(action.GetRetryPolicy().GetPerTryTimeout().GetNanos()).To(gomega.Equal(int32(500000000)))
+		g.Expect(action.GetRetryPolicy().GetRetryOn()).To(gomega.Equal("gateway-error,connect-failure"))
+		g.Expect(action.GetRetryPolicy().GetRetriableStatusCodes()).To(gomega.Equal([]uint32{503}))
+
+		// Without a timeout the route does not time out, and attempts: 0 disables retries.
+		action = routes[1].GetRoute()
+		g.Expect(action.GetTimeout().GetSeconds()).To(gomega.Equal(int64(0)))
+		g.Expect(action.GetTimeout().GetNanos()).To(gomega.Equal(int32(0)))
+		g.Expect(action.GetRetryPolicy()).To(gomega.BeNil())
+	})
+
 	t.Run("for no virtualservice but has destinationrule with consistentHash loadbalancer", func(t *testing.T) {
 		g := gomega.NewGomegaWithT(t)
 		meshConfig := mesh.DefaultMeshConfig()
@@ -587,6 +610,60 @@ var virtualServicePlain = model.Config{
 	},
 }
 
+var virtualServiceWithTimeoutAndRetries = model.Config{
+	ConfigMeta: model.ConfigMeta{
+		Type:    collections.IstioNetworkingV1Alpha3Virtualservices.Resource().Kind(),
+		Version: collections.IstioNetworkingV1Alpha3Virtualservices.Resource().Version(),
+		Name:    "acme",
+	},
+	Spec: &networking.VirtualService{
+		Hosts:    []string{},
+		Gateways: []string{"some-gateway"},
+		Http: []*networking.HTTPRoute{
+			{
+				Match: []*networking.HTTPMatchRequest{
+					{
+						Uri: &networking.StringMatch{
+							MatchType: &networking.StringMatch_Prefix{Prefix: "/slow"},
+						},
+					},
+				},
+				Route: []*networking.HTTPRouteDestination{
+					{
+						Destination: &networking.Destination{
+							Host: "*.example.org",
+							Port: &networking.PortSelector{
+								Number: 8484,
+							},
+						},
+					},
+				},
+				Timeout: &types.Duration{Seconds: 2},
+				Retries: &networking.HTTPRetry{
+					Attempts:      3,
+					PerTryTimeout: &types.Duration{Nanos: 500000000},
+					RetryOn:       "gateway-error,connect-failure,503",
+				},
+			},
+			{
+				Route: []*networking.HTTPRouteDestination{
+					{
+						Destination: &networking.Destination{
+							Host: "*.example.org",
+							Port: &networking.PortSelector{
+								Number: 8484,
+							},
+						},
+					},
+				},
+				Retries: &networking.HTTPRetry{
+					Attempts: 0,
+				},
+			},
+		},
+	},
+}
+
 var virtualServiceWithCatchAllRoute = model.Config{
 	ConfigMeta: model.ConfigMeta{
 		Type:    collections.IstioNetworkingV1Alpha3Virtualservices.Resource().Kind(),
@@ -498,11 +498,6 @@ func (s *DiscoveryServer) setProxyState(proxy *model.Proxy, push *model.PushCont
 	return nil
 }
 
-// DeltaAggregatedResources is not implemented.
-func (s *DiscoveryServer) DeltaAggregatedResources(stream ads.AggregatedDiscoveryService_DeltaAggregatedResourcesServer) error {
-	return status.Errorf(codes.Unimplemented, "not implemented")
-}
-
 // Compute and send the new configuration for a connection. This is blocking and may be slow
 // for large configs. The method will hold a lock on con.pushMutex.
 func (s *DiscoveryServer) pushConnection(con *XdsConnection, pushEv *XdsEvent) error {
@@ -717,6 +712,7 @@ func (s *DiscoveryServer) removeCon(conID string, con *XdsConnection) {
 		delete(s.adsSidecarIDConnectionsMap[node.ID], conID)
 		if len(s.adsSidecarIDConnectionsMap[node.ID]) == 0 {
 			delete(s.adsSidecarIDConnectionsMap, node.ID)
+			s.lastPushes.forget(node.ID)
 		}
 	}
 }
@@ -138,6 +138,18 @@ func (s *DiscoveryServer) InitDebug(mux *http.ServeMux, sctl *aggregate.Controll
 	s.addDebugHandler(mux, "/debug/push_status", "Last PushContext Details", s.PushStatusHandler)
 
 	s.addDebugHandler(mux, "/debug/inject", "Active inject template", s.InjectTemplateHandler(webhook))
+
+	if s.lastPushes != nil {
+		s.addDebugHandler(mux, "/debug/lastpush", "Most recent listener pushes for passed in proxyID", s.lastPushz)
+	}
+
+	if features.EnableDebugMeshConfig {
+		s.addDebugHandler(mux, "/debug/meshconfig", "Mesh config in use, as JSON or with ?format=yaml as YAML", s.MeshConfigz)
+	}
+
+	if features.EnableDebugTopology {
+		s.addDebugHandler(mux, "/debug/topology", "Services, instances and routing config of the mesh", s.Topologyz)
+	}
 }
 
 func (s *DiscoveryServer) addDebugHandler(mux *http.ServeMux, path string, help string,
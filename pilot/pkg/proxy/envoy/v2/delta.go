@@ -0,0 +1,413 @@
+// Copyright 2020 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v2
+
+import (
+	"bytes"
+	"errors"
+	"time"
+
+	xdsapi "github.com/envoyproxy/go-control-plane/envoy/api/v2"
+	ads "github.com/envoyproxy/go-control-plane/envoy/service/discovery/v2"
+	"github.com/golang/protobuf/proto"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+
+	"istio.io/istio/pilot/pkg/model"
+	"istio.io/istio/pilot/pkg/networking/util"
+	"istio.io/istio/pilot/pkg/util/sets"
+	"istio.io/pkg/monitoring"
+)
+
+var errDeltaStream = errors.New("state of the world xDS is not supported on a delta stream")
+
+// deltaStream adapts a delta ADS stream to DiscoveryStream, so that the connection can be tracked and queued for
+// pushes like any other. State of the world requests and responses are never exchanged on it.
+type deltaStream struct {
+	grpc.ServerStream
+}
+
+func (deltaStream) Send(*xdsapi.DiscoveryResponse) error {
+	return errDeltaStream
+}
+
+func (deltaStream) Recv() (*xdsapi.DiscoveryRequest, error) {
+	return nil, errDeltaStream
+}
+
+// deltaType describes how a resource type is served on a delta stream.
+type deltaType struct {
+	name          string
+	xdsType       XdsType
+	pushes        monitoring.Metric
+	sendErrPushes monitoring.Metric
+	pushTime      monitoring.Metric
+	reject        monitoring.Metric
+}
+
+var deltaTypes = map[string]deltaType{
+	ClusterType:  {"CDS", CDS, cdsPushes, cdsSendErrPushes, cdsPushTime, cdsReject},
+	EndpointType: {"EDS", EDS, edsPushes, edsSendErrPushes, edsPushTime, edsReject},
+	ListenerType: {"LDS", LDS, ldsPushes, ldsSendErrPushes, ldsPushTime, ldsReject},
+	RouteType:    {"RDS", RDS, rdsPushes, rdsSendErrPushes, rdsPushTime, rdsReject},
+}
+
+// deltaPushOrder is the order in which types are pushed on config changes, matching pushConnection: clusters before
+// the endpoints they reference, listeners before the routes they reference.
+var deltaPushOrder = []string{ClusterType, EndpointType, ListenerType, RouteType}
+
+// deltaResource is a named resource to be sent on a delta stream.
+type deltaResource struct {
+	name     string
+	resource proto.Message
+}
+
+// DeltaAggregatedResources implements the incremental ADS interface. Clusters, endpoints, listeners and routes are
+// all served on the stream: the first response for a type carries all of its resources, and each later push only
+// carries the resources added or changed since the previous response, plus the names of the removed ones. Requests
+// for other types are ignored, without closing the stream. Proxies which do not open a delta stream keep receiving
+// the full state from StreamAggregatedResources.
+func (s *DiscoveryServer) DeltaAggregatedResources(stream ads.AggregatedDiscoveryService_DeltaAggregatedResourcesServer) error {
+	peerInfo, ok := peer.FromContext(stream.Context())
+	peerAddr := "0.0.0.0"
+	if ok {
+		peerAddr = peerInfo.Addr.String()
+	}
+
+	if err := s.globalPushContext().InitContext(s.Env, nil, nil); err != nil {
+		adsLog.Warnf("Error reading config %v", err)
+		return err
+	}
+	con := newXdsConnection(peerAddr, deltaStream{stream})
+
+	// Resources last sent on this stream, by type URL and then by name. A type is watched once it has an entry.
+	sent := map[string]map[string][]byte{}
+
+	var receiveError error
+	reqChannel := make(chan *xdsapi.DeltaDiscoveryRequest, 1)
+	go deltaReceiveThread(con, stream, reqChannel, &receiveError)
+
+	for {
+		select {
+		case req, ok := <-reqChannel:
+			if !ok {
+				// Remote side closed connection.
+				return receiveError
+			}
+			if req.Node != nil && req.Node.Id != "" {
+				if cancel, err := s.initConnection(req.Node, con); err != nil {
+					return err
+				} else if cancel != nil {
+					defer cancel()
+				}
+			}
+			if con.node == nil {
+				return status.Errorf(codes.InvalidArgument, "the first delta request must include the node")
+			}
+			dt, f := deltaTypes[req.TypeUrl]
+			if !f {
+				adsLog.Warnf("ADS: Unknown delta watched resources %s %s %s", req.TypeUrl, peerAddr, con.ConID)
+				continue
+			}
+
+			if req.ErrorDetail != nil {
+				errCode := codes.Code(req.ErrorDetail.Code)
+				adsLog.Warnf("ADS:%s: delta ACK ERROR %v %s %s:%s", dt.name, peerAddr, con.ConID, errCode.String(), req.ErrorDetail.GetMessage())
+				incrementXDSRejects(dt.reject, con.node.ID, errCode.String())
+				continue
+			}
+
+			typeSent, watched := sent[req.TypeUrl]
+			changed := s.updateDeltaSubscriptions(con, req)
+			if watched && !changed {
+				if req.ResponseNonce != "" {
+					con.mu.Lock()
+					setDeltaNonce(con, req.TypeUrl, req.ResponseNonce, true)
+					con.mu.Unlock()
+				}
+				adsLog.Debugf("ADS:%s: delta ACK %s %s %s", dt.name, peerAddr, con.ConID, req.ResponseNonce)
+				continue
+			}
+
+			adsLog.Debugf("ADS:%s: delta REQ %s %v", dt.name, con.ConID, peerAddr)
+			if !watched {
+				typeSent = map[string][]byte{}
+				sent[req.TypeUrl] = typeSent
+				switch req.TypeUrl {
+				case ClusterType:
+					con.CDSWatch = true
+				case ListenerType:
+					con.LDSWatch = true
+				}
+			}
+			if err := s.pushDelta(con, stream, req.TypeUrl, typeSent, !watched, s.globalPushContext(), versionInfo()); err != nil {
+				return err
+			}
+
+		case pushEv := <-con.pushChannel:
+			err := s.pushDeltaConnection(con, stream, sent, pushEv)
+			pushEv.done()
+			if err != nil {
+				return nil
+			}
+		}
+	}
+}
+
+func deltaReceiveThread(con *XdsConnection, stream ads.AggregatedDiscoveryService_DeltaAggregatedResourcesServer,
+	reqChannel chan *xdsapi.DeltaDiscoveryRequest, errP *error) {
+	defer close(reqChannel) // indicates close of the remote side.
+	for {
+		req, err := stream.Recv()
+		if err != nil {
+			if isExpectedGRPCError(err) {
+				adsLog.Infof("ADS: %q %s delta stream terminated %v", con.PeerAddr, con.ConID, err)
+				return
+			}
+			*errP = err
+			adsLog.Errorf("ADS: %q %s delta stream terminated with error: %v", con.PeerAddr, con.ConID, err)
+			totalXDSInternalErrors.Increment()
+			return
+		}
+		select {
+		case reqChannel <- req:
+		case <-stream.Context().Done():
+			adsLog.Infof("ADS: %q %s delta stream terminated with stream closed", con.PeerAddr, con.ConID)
+			return
+		}
+	}
+}
+
+// updateDeltaSubscriptions applies the subscription changes of req to the routes or clusters watched by con, and
+// reports whether they changed. Clusters and listeners are always watched as a whole.
+func (s *DiscoveryServer) updateDeltaSubscriptions(con *XdsConnection, req *xdsapi.DeltaDiscoveryRequest) bool {
+	var names []string
+	switch req.TypeUrl {
+	case RouteType:
+		names = con.Routes
+	case EndpointType:
+		names = con.Clusters
+	default:
+		return false
+	}
+
+	previous := sets.NewSet(names...)
+	current := sets.NewSet(names...).Insert(req.ResourceNamesSubscribe...)
+	for _, name := range req.ResourceNamesUnsubscribe {
+		delete(current, name)
+	}
+	added, removed := current.Difference(previous), previous.Difference(current)
+	if len(added) == 0 && len(removed) == 0 {
+		return false
+	}
+
+	if req.TypeUrl == EndpointType {
+		s.updateEdsClients(added, removed, con)
+		con.Clusters = current.UnsortedList()
+	} else {
+		con.Routes = current.UnsortedList()
+	}
+	return true
+}
+
+// setDeltaNonce records nonce as the last nonce sent, or acked, for typeURL on con. con.mu must be held.
+func setDeltaNonce(con *XdsConnection, typeURL, nonce string, acked bool) {
+	switch typeURL {
+	case ClusterType:
+		if acked {
+			con.ClusterNonceAcked = nonce
+		} else {
+			con.ClusterNonceSent = nonce
+		}
+	case EndpointType:
+		if acked {
+			con.EndpointNonceAcked = nonce
+		} else {
+			con.EndpointNonceSent = nonce
+		}
+	case ListenerType:
+		if acked {
+			con.ListenerNonceAcked = nonce
+		} else {
+			con.ListenerNonceSent = nonce
+		}
+	case RouteType:
+		if acked {
+			con.RouteNonceAcked = nonce
+		} else {
+			con.RouteNonceSent = nonce
+		}
+	}
+}
+
+// pushDeltaConnection is the delta counterpart of pushConnection. Only the watched types are pushed.
+func (s *DiscoveryServer) pushDeltaConnection(con *XdsConnection, stream ads.AggregatedDiscoveryService_DeltaAggregatedResourcesServer,
+	sent map[string]map[string][]byte, pushEv *XdsEvent) error {
+	// Endpoint only pushes.
+	if pushEv.edsUpdatedServices != nil {
+		if !ProxyNeedsPush(con.node, pushEv) {
+			return nil
+		}
+		if typeSent, f := sent[EndpointType]; f {
+			return s.pushDelta(con, stream, EndpointType, typeSent, false, pushEv.push, versionInfo())
+		}
+		return nil
+	}
+
+	if err := s.updateProxy(con.node, pushEv.push); err != nil {
+		return nil
+	}
+	if !ProxyNeedsPush(con.node, pushEv) {
+		adsLog.Debugf("Skipping delta push to %v, no updates required", con.ConID)
+		return nil
+	}
+
+	pushTypes := PushTypeFor(con.node, pushEv)
+	version := versionInfo()
+	for _, typeURL := range deltaPushOrder {
+		typeSent, f := sent[typeURL]
+		if !f || !pushTypes[deltaTypes[typeURL].xdsType] {
+			continue
+		}
+		if err := s.pushDelta(con, stream, typeURL, typeSent, false, pushEv.push, version); err != nil {
+			return err
+		}
+	}
+	proxiesConvergeDelay.Record(time.Since(pushEv.start).Seconds())
+	return nil
+}
+
+// pushDelta sends the resources of typeURL which changed since the previous response. Nothing is sent when nothing
+// changed, unless initial is set: the first response for a type is always sent, so that the proxy does not keep
+// waiting for it.
+func (s *DiscoveryServer) pushDelta(con *XdsConnection, stream ads.AggregatedDiscoveryService_DeltaAggregatedResourcesServer,
+	typeURL string, sent map[string][]byte, initial bool, push *model.PushContext, version string) error {
+	dt := deltaTypes[typeURL]
+	pushStart := time.Now()
+
+	var resources []deltaResource
+	var rawListeners []*xdsapi.Listener
+	switch typeURL {
+	case ClusterType:
+		rawClusters := s.generateRawClusters(con.node, push)
+		if s.DebugConfigs {
+			con.CDSClusters = rawClusters
+		}
+		for _, c := range rawClusters {
+			resources = append(resources, deltaResource{c.Name, c})
+		}
+	case EndpointType:
+		for _, clusterName := range con.Clusters {
+			if l := s.generateEndpoints(clusterName, con.node, push, nil); l != nil {
+				resources = append(resources, deltaResource{clusterName, l})
+			}
+		}
+	case ListenerType:
+		rawListeners = s.generateRawListeners(con, push)
+		if s.DebugConfigs {
+			con.LDSListeners = rawListeners
+		}
+		resources = listenerDeltaResources(rawListeners)
+	case RouteType:
+		for _, r := range s.generateRawRoutes(con, push) {
+			resources = append(resources, deltaResource{r.Name, r})
+		}
+	}
+
+	response, err := deltaDiscoveryResponse(typeURL, sent, resources, version, push.Version)
+	if err != nil {
+		return err
+	}
+	if !initial && len(response.Resources) == 0 && len(response.RemovedResources) == 0 {
+		adsLog.Debugf("%s: delta PUSH for node:%s skipped, no changes", dt.name, con.node.ID)
+		return nil
+	}
+
+	err = stream.Send(response)
+	dt.pushTime.Record(time.Since(pushStart).Seconds())
+	if err != nil {
+		adsLog.Warnf("%s: delta send failure %s: %v", dt.name, con.ConID, err)
+		recordSendError(dt.sendErrPushes, err)
+		return err
+	}
+	con.mu.Lock()
+	setDeltaNonce(con, typeURL, response.Nonce, false)
+	con.mu.Unlock()
+	dt.pushes.Increment()
+	if typeURL == ListenerType {
+		s.lastPushes.record(con.node.ID, version, response.Nonce, rawListeners)
+	}
+
+	adsLog.Infof("%s: delta PUSH for node:%s resources:%d updated:%d removed:%d",
+		dt.name, con.node.ID, len(resources), len(response.Resources), len(response.RemovedResources))
+	return nil
+}
+
+func listenerDeltaResources(ls []*xdsapi.Listener) []deltaResource {
+	resources := make([]deltaResource, 0, len(ls))
+	for _, ll := range ls {
+		if ll == nil {
+			adsLog.Errora("Nil listener ", ll)
+			totalXDSInternalErrors.Increment()
+			continue
+		}
+		resources = append(resources, deltaResource{ll.Name, ll})
+	}
+	return resources
+}
+
+// deltaDiscoveryResponse returns a delta response with the resources which were added or changed relative to sent,
+// and the names of the resources in sent which are no longer present. sent maps resource names to their serialized
+// form and is updated to reflect resources.
+func deltaDiscoveryResponse(typeURL string, sent map[string][]byte, resources []deltaResource, version string,
+	noncePrefix string) (*xdsapi.DeltaDiscoveryResponse, error) {
+	resp := &xdsapi.DeltaDiscoveryResponse{
+		TypeUrl:           typeURL,
+		SystemVersionInfo: version,
+		Nonce:             nonce(noncePrefix),
+	}
+
+	current := make(map[string]bool, len(resources))
+	for _, r := range resources {
+		current[r.name] = true
+
+		// Serialize deterministically, so that unchanged resources compare equal.
+		b := proto.NewBuffer(nil)
+		b.SetDeterministic(true)
+		if err := b.Marshal(r.resource); err != nil {
+			return nil, err
+		}
+		if previous, f := sent[r.name]; f && bytes.Equal(previous, b.Bytes()) {
+			continue
+		}
+		sent[r.name] = b.Bytes()
+		resp.Resources = append(resp.Resources, &xdsapi.Resource{
+			Name:     r.name,
+			Version:  version,
+			Resource: util.MessageToAny(r.resource),
+		})
+	}
+
+	for name := range sent {
+		if !current[name] {
+			resp.RemovedResources = append(resp.RemovedResources, name)
+			delete(sent, name)
+		}
+	}
+
+	return resp, nil
+}
@@ -0,0 +1,182 @@
+// Copyright 2020 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v2
+
+import (
+	"context"
+	"io"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	xdsapi "github.com/envoyproxy/go-control-plane/envoy/api/v2"
+	core "github.com/envoyproxy/go-control-plane/envoy/api/v2/core"
+	"google.golang.org/grpc"
+)
+
+func testListener(name string, port uint32) *xdsapi.Listener {
+	return &xdsapi.Listener{
+		Name: name,
+		Address: &core.Address{Address: &core.Address_SocketAddress{SocketAddress: &core.SocketAddress{
+			Address:       "0.0.0.0",
+			PortSpecifier: &core.SocketAddress_PortValue{PortValue: port},
+		}}},
+	}
+}
+
+func deltaResourceNames(resp *xdsapi.DeltaDiscoveryResponse) []string {
+	names := make([]string, 0, len(resp.Resources))
+	for _, r := range resp.Resources {
+		names = append(names, r.Name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func TestDeltaDiscoveryResponse(t *testing.T) {
+	sent := map[string][]byte{}
+
+	// The first response on a stream carries every listener.
+	resp, err := deltaDiscoveryResponse(ListenerType, sent, listenerDeltaResources([]*xdsapi.Listener{
+		testListener("a", 80),
+		testListener("b", 81),
+		testListener("c", 82),
+	}), "v1", "1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := deltaResourceNames(resp), []string{"a", "b", "c"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("initial push: got resources %v, want %v", got, want)
+	}
+	if len(resp.RemovedResources) != 0 {
+		t.Fatalf("initial push: got removed resources %v, want none", resp.RemovedResources)
+	}
+
+	// Afterwards, only the changed, added and removed listeners are sent.
+	resp, err = deltaDiscoveryResponse(ListenerType, sent, listenerDeltaResources([]*xdsapi.Listener{
+		testListener("a", 80),
+		testListener("b", 8081),
+		testListener("d", 83),
+	}), "v2", "2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := deltaResourceNames(resp), []string{"b", "d"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("incremental push: got resources %v, want %v", got, want)
+	}
+	if got, want := resp.RemovedResources, []string{"c"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("incremental push: got removed resources %v, want %v", got, want)
+	}
+
+	// Nothing is sent when nothing changed.
+	resp, err = deltaDiscoveryResponse(ListenerType, sent, listenerDeltaResources([]*xdsapi.Listener{
+		testListener("a", 80),
+		testListener("b", 8081),
+		testListener("d", 83),
+	}), "v3", "3")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Resources) != 0 || len(resp.RemovedResources) != 0 {
+		t.Errorf("unchanged push: got resources %v removed %v, want none", deltaResourceNames(resp), resp.RemovedResources)
+	}
+}
+
+type fakeDeltaStream struct {
+	grpc.ServerStream
+	requests  chan *xdsapi.DeltaDiscoveryRequest
+	responses chan *xdsapi.DeltaDiscoveryResponse
+}
+
+func (f *fakeDeltaStream) Send(resp *xdsapi.DeltaDiscoveryResponse) error {
+	f.responses <- resp
+	return nil
+}
+
+func (f *fakeDeltaStream) Recv() (*xdsapi.DeltaDiscoveryRequest, error) {
+	req, ok := <-f.requests
+	if !ok {
+		return nil, io.EOF
+	}
+	return req, nil
+}
+
+func (f *fakeDeltaStream) Context() context.Context {
+	return context.Background()
+}
+
+func (f *fakeDeltaStream) expectResponse(t *testing.T, typeURL string) *xdsapi.DeltaDiscoveryResponse {
+	t.Helper()
+	select {
+	case resp := <-f.responses:
+		if resp.TypeUrl != typeURL {
+			t.Fatalf("got response for %s, want %s", resp.TypeUrl, typeURL)
+		}
+		return resp
+	case <-time.After(5 * time.Second):
+		t.Fatalf("timed out waiting for a %s response", typeURL)
+	}
+	return nil
+}
+
+func TestDeltaAggregatedResources(t *testing.T) {
+	s := SetupDiscoveryServer(t, createEndpoints(1, 1)...)
+	stream := &fakeDeltaStream{
+		requests:  make(chan *xdsapi.DeltaDiscoveryRequest),
+		responses: make(chan *xdsapi.DeltaDiscoveryResponse, 1),
+	}
+	done := make(chan error)
+	go func() {
+		done <- s.DeltaAggregatedResources(stream)
+	}()
+
+	// Envoy asks for clusters first, and only asks for listeners once clusters are received.
+	stream.requests <- &xdsapi.DeltaDiscoveryRequest{
+		Node:    &core.Node{Id: "sidecar~1.1.1.1~app.default~default.svc.cluster.local"},
+		TypeUrl: ClusterType,
+	}
+	cds := stream.expectResponse(t, ClusterType)
+	const cluster = "outbound|80||foo-0.com"
+	names := deltaResourceNames(cds)
+	if i := sort.SearchStrings(names, cluster); i == len(names) || names[i] != cluster {
+		t.Fatalf("got clusters %v, want %s", names, cluster)
+	}
+
+	// Acks and unknown types get no response, and leave the stream open.
+	stream.requests <- &xdsapi.DeltaDiscoveryRequest{TypeUrl: ClusterType, ResponseNonce: cds.Nonce}
+	stream.requests <- &xdsapi.DeltaDiscoveryRequest{TypeUrl: "type.googleapis.com/unknown"}
+
+	stream.requests <- &xdsapi.DeltaDiscoveryRequest{TypeUrl: EndpointType, ResourceNamesSubscribe: []string{cluster}}
+	eds := stream.expectResponse(t, EndpointType)
+	if got, want := deltaResourceNames(eds), []string{cluster}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("got endpoints %v, want %v", got, want)
+	}
+
+	stream.requests <- &xdsapi.DeltaDiscoveryRequest{TypeUrl: ListenerType}
+	if lds := stream.expectResponse(t, ListenerType); len(lds.Resources) == 0 {
+		t.Fatal("got no listeners")
+	}
+
+	close(stream.requests)
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("stream closed with error: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for the stream to close")
+	}
+}
@@ -94,6 +94,12 @@ type DiscoveryServer struct {
 	// Defaults to false, can be enabled with PILOT_DEBUG_ADSZ_CONFIG=1
 	DebugConfigs bool
 
+	// MaxListeners is the number of listeners of a proxy above which a warning is logged. Zero means no limit.
+	MaxListeners int
+
+	// TruncateListeners drops the listeners of a proxy above MaxListeners, except for the virtual listeners.
+	TruncateListeners bool
+
 	// mutex protecting global structs updated or read by ADS service, including EDSUpdates and
 	// shards.
 	mutex sync.RWMutex
@@ -121,6 +127,10 @@ type DiscoveryServer struct {
 	// This is a map due to an edge case during envoy restart whereby the 'old' envoy
 	// reconnects after the 'new/restarted' envoy
 	adsSidecarIDConnectionsMap map[string]map[string]*XdsConnection
+
+	// lastPushes records the most recent listener pushes of each proxy for /debug/lastpush.
+	// It is nil unless enabled with PILOT_DEBUG_LAST_PUSHES.
+	lastPushes *lastPushLog
 }
 
 // EndpointShards holds the set of endpoint shards of a service. Registries update
@@ -157,6 +167,9 @@ func NewDiscoveryServer(env *model.Environment, plugins []string) *DiscoveryServ
 		adsClients:                 map[string]*XdsConnection{},
 		adsSidecarIDConnectionsMap: map[string]map[string]*XdsConnection{},
 	}
+	if features.DebugLastPushes > 0 {
+		out.lastPushes = newLastPushLog(features.DebugLastPushes)
+	}
 
 	// Flush cached discovery responses when detecting jwt public key change.
 	model.JwtKeyResolver.PushFunc = func() {
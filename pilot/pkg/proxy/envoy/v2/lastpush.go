@@ -0,0 +1,137 @@
+// Copyright 2020 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v2
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+
+	xdsapi "github.com/envoyproxy/go-control-plane/envoy/api/v2"
+	"github.com/golang/protobuf/jsonpb"
+)
+
+// listenerPush is a listener push to a proxy, as recorded for /debug/lastpush.
+type listenerPush struct {
+	Time      time.Time
+	Version   string
+	Nonce     string
+	Listeners []*xdsapi.Listener
+}
+
+// lastPushLog keeps a fixed size ring of the most recent listener pushes of each connected proxy. The methods of a
+// nil log do nothing, so that recording can be left unconditional.
+type lastPushLog struct {
+	mu     sync.Mutex
+	size   int
+	pushes map[string][]listenerPush
+}
+
+func newLastPushLog(size int) *lastPushLog {
+	return &lastPushLog{size: size, pushes: map[string][]listenerPush{}}
+}
+
+// record adds a push to the given proxy, dropping its oldest push once the ring is full. The listeners are
+// kept as is, they are not modified once generated.
+func (l *lastPushLog) record(proxyID, version, nonce string, listeners []*xdsapi.Listener) {
+	if l == nil {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	pushes := append(l.pushes[proxyID], listenerPush{
+		Time:      time.Now(),
+		Version:   version,
+		Nonce:     nonce,
+		Listeners: listeners,
+	})
+	if len(pushes) > l.size {
+		pushes = append(pushes[:0:0], pushes[len(pushes)-l.size:]...)
+	}
+	l.pushes[proxyID] = pushes
+}
+
+// get returns the recorded pushes to the given proxy, oldest first.
+func (l *lastPushLog) get(proxyID string) []listenerPush {
+	if l == nil {
+		return nil
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return append([]listenerPush(nil), l.pushes[proxyID]...)
+}
+
+// forget drops the pushes to the given proxy, once it is no longer connected.
+func (l *lastPushLog) forget(proxyID string) {
+	if l == nil {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	delete(l.pushes, proxyID)
+}
+
+// lastPushz serves the most recent listener pushes to the proxy passed in proxyID, oldest first.
+// It is mapped to /debug/lastpush
+func (s *DiscoveryServer) lastPushz(w http.ResponseWriter, req *http.Request) {
+	proxyID := req.URL.Query().Get("proxyID")
+	if proxyID == "" {
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte("You must provide a proxyID in the query string"))
+		return
+	}
+	pushes := s.lastPushes.get(proxyID)
+	if len(pushes) == 0 {
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte("No listener push recorded for this proxy"))
+		return
+	}
+
+	type pushInfo struct {
+		Time      time.Time         `json:"time"`
+		Version   string            `json:"version"`
+		Nonce     string            `json:"nonce"`
+		Listeners []json.RawMessage `json:"listeners"`
+	}
+	jsonm := &jsonpb.Marshaler{}
+	out := make([]pushInfo, 0, len(pushes))
+	for _, p := range pushes {
+		info := pushInfo{Time: p.Time, Version: p.Version, Nonce: p.Nonce}
+		for _, l := range p.Listeners {
+			if l == nil {
+				continue
+			}
+			var b bytes.Buffer
+			if err := jsonm.Marshal(&b, l); err != nil {
+				w.WriteHeader(http.StatusInternalServerError)
+				_, _ = w.Write([]byte(err.Error()))
+				return
+			}
+			info.Listeners = append(info.Listeners, b.Bytes())
+		}
+		out = append(out, info)
+	}
+
+	b, err := json.MarshalIndent(out, "", "  ")
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		_, _ = w.Write([]byte(err.Error()))
+		return
+	}
+	w.Header().Add("Content-Type", "application/json")
+	_, _ = w.Write(b)
+}
@@ -0,0 +1,89 @@
+// Copyright 2020 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v2
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"istio.io/istio/pilot/pkg/model"
+)
+
+func TestLastPushz(t *testing.T) {
+	s := SetupDiscoveryServer(t, createEndpoints(1, 1)...)
+	s.lastPushes = newLastPushLog(2)
+
+	proxy := &model.Proxy{
+		Type:            model.SidecarProxy,
+		IPAddresses:     []string{"10.3.3.3"},
+		ID:              "app.default",
+		ConfigNamespace: "default",
+		Metadata:        &model.NodeMetadata{},
+	}
+	push := s.globalPushContext()
+	proxy.SetSidecarScope(push)
+	con := newXdsConnection("10.3.3.3:1234", &fakeStream{})
+	con.node = proxy
+
+	for _, version := range []string{"1", "2", "3"} {
+		if err := s.pushLds(con, push, version); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	get := func(proxyID string) *httptest.ResponseRecorder {
+		req, err := http.NewRequest("GET", "/debug/lastpush?proxyID="+proxyID, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		rr := httptest.NewRecorder()
+		http.HandlerFunc(s.lastPushz).ServeHTTP(rr, req)
+		return rr
+	}
+
+	rr := get(proxy.ID)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("wanted response code 200, got %v: %s", rr.Code, rr.Body.String())
+	}
+	var got []struct {
+		Version   string            `json:"version"`
+		Nonce     string            `json:"nonce"`
+		Listeners []json.RawMessage `json:"listeners"`
+	}
+	if err := json.Unmarshal(rr.Body.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+	var versions []string
+	for _, p := range got {
+		versions = append(versions, p.Version)
+		if p.Nonce == "" || len(p.Listeners) == 0 {
+			t.Errorf("push %s: got nonce %q and %d listeners, want a nonce and the pushed listeners", p.Version, p.Nonce, len(p.Listeners))
+		}
+	}
+	if want := []string{"2", "3"}; !reflect.DeepEqual(versions, want) {
+		t.Errorf("got pushes %v, want the last pushes %v", versions, want)
+	}
+
+	if rr := get("unknown.default"); rr.Code != http.StatusNotFound {
+		t.Errorf("unknown proxy: wanted response code 404, got %v", rr.Code)
+	}
+	s.lastPushes.forget(proxy.ID)
+	if rr := get(proxy.ID); rr.Code != http.StatusNotFound {
+		t.Errorf("disconnected proxy: wanted response code 404, got %v", rr.Code)
+	}
+}
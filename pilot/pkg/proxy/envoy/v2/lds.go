@@ -20,6 +20,7 @@ import (
 	xdsapi "github.com/envoyproxy/go-control-plane/envoy/api/v2"
 
 	"istio.io/istio/pilot/pkg/model"
+	networking "istio.io/istio/pilot/pkg/networking/core/v1alpha3"
 	"istio.io/istio/pilot/pkg/networking/util"
 )
 
@@ -40,6 +41,7 @@ func (s *DiscoveryServer) pushLds(con *XdsConnection, push *model.PushContext, v
 		return err
 	}
 	ldsPushes.Increment()
+	s.lastPushes.record(con.node.ID, version, response.Nonce, rawListeners)
 
 	adsLog.Infof("LDS: PUSH for node:%s listeners:%d", con.node.ID, len(rawListeners))
 	return nil
@@ -56,7 +58,43 @@ func (s *DiscoveryServer) generateRawListeners(con *XdsConnection, push *model.P
 			// Instead of panic, which will break down the whole cluster. Just ignore it here, let envoy process it.
 		}
 	}
-	return rawListeners
+	return s.capListeners(con.node.ID, rawListeners)
+}
+
+// capListeners warns when a proxy gets more than MaxListeners listeners, which usually means a misconfiguration, and
+// keeps only MaxListeners of them if TruncateListeners is set. The virtual listeners are always kept, since the
+// proxy cannot receive any redirected traffic without them.
+func (s *DiscoveryServer) capListeners(nodeID string, ls []*xdsapi.Listener) []*xdsapi.Listener {
+	if s.MaxListeners <= 0 || len(ls) <= s.MaxListeners {
+		return ls
+	}
+	ldsOverCapPushes.Increment()
+	if !s.TruncateListeners {
+		adsLog.Warnf("LDS: node:%s has %d listeners, more than the limit of %d", nodeID, len(ls), s.MaxListeners)
+		return ls
+	}
+
+	isVirtual := func(l *xdsapi.Listener) bool {
+		return l != nil && (l.Name == networking.VirtualOutboundListenerName || l.Name == networking.VirtualInboundListenerName)
+	}
+	others := s.MaxListeners
+	for _, l := range ls {
+		if isVirtual(l) {
+			others--
+		}
+	}
+	out := make([]*xdsapi.Listener, 0, s.MaxListeners)
+	for _, l := range ls {
+		if isVirtual(l) {
+			out = append(out, l)
+		} else if others > 0 {
+			out = append(out, l)
+			others--
+		}
+	}
+	adsLog.Warnf("LDS: node:%s has %d listeners, more than the limit of %d; TRUNCATED to %d listeners",
+		nodeID, len(ls), s.MaxListeners, len(out))
+	return out
 }
 
 // LdsDiscoveryResponse returns a list of listeners for the given environment and source node.
@@ -0,0 +1,86 @@
+// Copyright 2020 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v2
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	xdsapi "github.com/envoyproxy/go-control-plane/envoy/api/v2"
+
+	networking "istio.io/istio/pilot/pkg/networking/core/v1alpha3"
+)
+
+func TestCapListeners(t *testing.T) {
+	listeners := func(names ...string) []*xdsapi.Listener {
+		var ls []*xdsapi.Listener
+		for _, n := range names {
+			ls = append(ls, &xdsapi.Listener{Name: n})
+		}
+		return ls
+	}
+	var overCap []string
+	for i := 0; i < 5; i++ {
+		overCap = append(overCap, fmt.Sprintf("0.0.0.0_%d", 8000+i))
+	}
+	overCap = append(overCap, networking.VirtualOutboundListenerName, networking.VirtualInboundListenerName)
+
+	cases := []struct {
+		name     string
+		max      int
+		truncate bool
+		in       []string
+		want     []string
+	}{
+		{
+			name: "no limit",
+			in:   overCap,
+			want: overCap,
+		},
+		{
+			name: "under the limit",
+			max:  10,
+			in:   overCap,
+			want: overCap,
+		},
+		{
+			name: "over the limit without truncation",
+			max:  4,
+			in:   overCap,
+			want: overCap,
+		},
+		{
+			name:     "over the limit with truncation keeps the virtual listeners",
+			max:      4,
+			truncate: true,
+			in:       overCap,
+			want: []string{"0.0.0.0_8000", "0.0.0.0_8001",
+				networking.VirtualOutboundListenerName, networking.VirtualInboundListenerName},
+		},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &DiscoveryServer{MaxListeners: tt.max, TruncateListeners: tt.truncate}
+			var got []string
+			for _, l := range s.capListeners("node", listeners(tt.in...)) {
+				got = append(got, l.Name)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got listeners %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,72 @@
+// Copyright 2020 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v2
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gogo/protobuf/proto"
+
+	meshconfig "istio.io/api/mesh/v1alpha1"
+
+	"istio.io/istio/pkg/util/gogoprotomarshal"
+)
+
+const redactedValue = "<redacted>"
+
+// MeshConfigz dumps the mesh config held by the environment, after defaults and overrides are applied. It can differ
+// from the mesh ConfigMap if a reload has not been processed yet. The format query parameter selects "json" (the
+// default) or "yaml". Secrets are redacted.
+func (s *DiscoveryServer) MeshConfigz(w http.ResponseWriter, req *http.Request) {
+	mesh := s.Env.Mesh()
+	if mesh == nil {
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = fmt.Fprint(w, "no mesh config loaded")
+		return
+	}
+	mesh = redactMeshConfig(mesh)
+
+	var out string
+	var err error
+	switch format := req.URL.Query().Get("format"); format {
+	case "", "json":
+		w.Header().Set("Content-Type", "application/json")
+		out, err = gogoprotomarshal.ToJSONWithIndent(mesh, "  ")
+	case "yaml":
+		w.Header().Set("Content-Type", "application/yaml")
+		out, err = gogoprotomarshal.ToYAML(mesh)
+	default:
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = fmt.Fprintf(w, "unsupported format %q, want json or yaml", format)
+		return
+	}
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		_, _ = fmt.Fprintf(w, "unable to marshal mesh config: %v", err)
+		return
+	}
+	_, _ = fmt.Fprint(w, out)
+}
+
+// redactMeshConfig returns mesh, or a copy of it if it embeds secrets, with the secrets replaced by a placeholder.
+func redactMeshConfig(mesh *meshconfig.MeshConfig) *meshconfig.MeshConfig {
+	if mesh.GetDefaultConfig().GetTracing().GetLightstep().GetAccessToken() == "" {
+		return mesh
+	}
+	mesh = proto.Clone(mesh).(*meshconfig.MeshConfig)
+	mesh.DefaultConfig.Tracing.GetLightstep().AccessToken = redactedValue
+	return mesh
+}
@@ -0,0 +1,91 @@
+// Copyright 2020 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v2
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	meshconfig "istio.io/api/mesh/v1alpha1"
+)
+
+func TestMeshConfigz(t *testing.T) {
+	s := SetupDiscoveryServer(t)
+	s.Env.Mesh().IngressClass = "debug-ingress"
+
+	cases := []struct {
+		query string
+		code  int
+		want  string
+	}{
+		{"", http.StatusOK, `"ingressClass": "debug-ingress"`},
+		{"?format=json", http.StatusOK, `"ingressClass": "debug-ingress"`},
+		{"?format=yaml", http.StatusOK, "ingressClass: debug-ingress"},
+		{"?format=xml", http.StatusBadRequest, "unsupported format"},
+	}
+	for _, c := range cases {
+		t.Run(c.query, func(t *testing.T) {
+			req, err := http.NewRequest("GET", "/debug/meshconfig"+c.query, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			rr := httptest.NewRecorder()
+			http.HandlerFunc(s.MeshConfigz).ServeHTTP(rr, req)
+			if rr.Code != c.code {
+				t.Fatalf("wanted response code %v, got %v: %s", c.code, rr.Code, rr.Body.String())
+			}
+			if !strings.Contains(rr.Body.String(), c.want) {
+				t.Errorf("got body %s, want it to contain %s", rr.Body.String(), c.want)
+			}
+		})
+	}
+}
+
+func TestMeshConfigzRedactsSecrets(t *testing.T) {
+	const token = "lightstep-secret-token"
+	s := SetupDiscoveryServer(t)
+	s.Env.Mesh().DefaultConfig.Tracing = &meshconfig.Tracing{
+		Tracer: &meshconfig.Tracing_Lightstep_{Lightstep: &meshconfig.Tracing_Lightstep{
+			Address:     "lightstep-satellite:8080",
+			AccessToken: token,
+		}},
+	}
+
+	for _, query := range []string{"?format=json", "?format=yaml"} {
+		t.Run(query, func(t *testing.T) {
+			req, err := http.NewRequest("GET", "/debug/meshconfig"+query, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			rr := httptest.NewRecorder()
+			http.HandlerFunc(s.MeshConfigz).ServeHTTP(rr, req)
+			if rr.Code != http.StatusOK {
+				t.Fatalf("wanted response code %v, got %v: %s", http.StatusOK, rr.Code, rr.Body.String())
+			}
+			if strings.Contains(rr.Body.String(), token) {
+				t.Errorf("got body %s, want the access token to be redacted", rr.Body.String())
+			}
+			// JSON escapes the angle brackets of the placeholder.
+			if !strings.Contains(rr.Body.String(), "redacted") {
+				t.Errorf("got body %s, want the access token to be redacted", rr.Body.String())
+			}
+		})
+	}
+	if got := s.Env.Mesh().DefaultConfig.Tracing.GetLightstep().AccessToken; got != token {
+		t.Errorf("got access token %q in the live mesh config, want it unchanged", got)
+	}
+}
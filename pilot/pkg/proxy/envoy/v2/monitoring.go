@@ -106,6 +106,7 @@ var (
 	ldsPushes         = pushes.With(typeTag.Value("lds"))
 	ldsSendErrPushes  = pushes.With(typeTag.Value("lds_senderr"))
 	ldsBuildErrPushes = pushes.With(typeTag.Value("lds_builderr"))
+	ldsOverCapPushes  = pushes.With(typeTag.Value("lds_overcap"))
 	rdsPushes         = pushes.With(typeTag.Value("rds"))
 	rdsSendErrPushes  = pushes.With(typeTag.Value("rds_senderr"))
 	rdsBuildErrPushes = pushes.With(typeTag.Value("rds_builderr"))
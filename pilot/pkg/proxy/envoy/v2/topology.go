@@ -0,0 +1,170 @@
+// Copyright 2020 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v2
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+
+	networking "istio.io/api/networking/v1alpha3"
+
+	"istio.io/istio/pilot/pkg/model"
+	"istio.io/istio/pkg/config/host"
+	"istio.io/istio/pkg/config/schema/collections"
+)
+
+// Topology is the mesh topology served by /debug/topology.
+type Topology struct {
+	Services []*TopologyService `json:"services"`
+	// Next is the offset of the next page, if the services were truncated by the limit.
+	Next int `json:"next,omitempty"`
+}
+
+// TopologyService is a service in the mesh topology, with its instances and the routing config that applies to it.
+type TopologyService struct {
+	Hostname         host.Name           `json:"hostname"`
+	Namespace        string              `json:"namespace"`
+	Address          string              `json:"address,omitempty"`
+	Ports            model.PortList      `json:"ports"`
+	Instances        []*TopologyInstance `json:"instances"`
+	VirtualServices  []string            `json:"virtualServices,omitempty"`
+	DestinationRules []string            `json:"destinationRules,omitempty"`
+}
+
+// TopologyInstance is an instance of a service in the mesh topology.
+type TopologyInstance struct {
+	Address        string            `json:"address"`
+	Port           uint32            `json:"port"`
+	ServicePort    string            `json:"servicePort"`
+	Labels         map[string]string `json:"labels,omitempty"`
+	ServiceAccount string            `json:"serviceAccount,omitempty"`
+	Locality       string            `json:"locality,omitempty"`
+}
+
+// Topologyz dumps the services of the mesh, their instances, and the virtual services and destination rules that
+// apply to them. The output can be restricted to a namespace with the namespace query parameter, and paged with the
+// offset and limit query parameters.
+func (s *DiscoveryServer) Topologyz(w http.ResponseWriter, req *http.Request) {
+	_ = req.ParseForm()
+	namespace := req.Form.Get("namespace")
+	offset, err := topologyQueryInt(req, "offset")
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = fmt.Fprint(w, err)
+		return
+	}
+	limit, err := topologyQueryInt(req, "limit")
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = fmt.Fprint(w, err)
+		return
+	}
+
+	all, err := s.Env.ServiceDiscovery.Services()
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		_, _ = fmt.Fprintf(w, "unable to list services: %v", err)
+		return
+	}
+	services := make([]*model.Service, 0, len(all))
+	for _, svc := range all {
+		if namespace == "" || svc.Attributes.Namespace == namespace {
+			services = append(services, svc)
+		}
+	}
+	sort.Slice(services, func(i, j int) bool { return services[i].Hostname < services[j].Hostname })
+
+	topology := &Topology{Services: []*TopologyService{}}
+	if offset < len(services) {
+		services = services[offset:]
+	} else {
+		services = nil
+	}
+	if limit > 0 && limit < len(services) {
+		services = services[:limit]
+		topology.Next = offset + limit
+	}
+
+	virtualServices, _ := s.Env.IstioConfigStore.List(
+		collections.IstioNetworkingV1Alpha3Virtualservices.Resource().GroupVersionKind(), model.NamespaceAll)
+	destinationRules, _ := s.Env.IstioConfigStore.List(
+		collections.IstioNetworkingV1Alpha3Destinationrules.Resource().GroupVersionKind(), model.NamespaceAll)
+
+	for _, svc := range services {
+		ts := &TopologyService{
+			Hostname:  svc.Hostname,
+			Namespace: svc.Attributes.Namespace,
+			Address:   svc.Address,
+			Ports:     svc.Ports,
+			Instances: []*TopologyInstance{},
+		}
+		for _, p := range svc.Ports {
+			instances, err := s.Env.ServiceDiscovery.InstancesByPort(svc, p.Port, nil)
+			if err != nil {
+				continue
+			}
+			for _, si := range instances {
+				ts.Instances = append(ts.Instances, &TopologyInstance{
+					Address:        si.Endpoint.Address,
+					Port:           si.Endpoint.EndpointPort,
+					ServicePort:    si.ServicePort.Name,
+					Labels:         si.Endpoint.Labels,
+					ServiceAccount: si.Endpoint.ServiceAccount,
+					Locality:       si.Endpoint.Locality,
+				})
+			}
+		}
+		for _, c := range virtualServices {
+			vs := c.Spec.(*networking.VirtualService)
+			for _, h := range vs.Hosts {
+				if model.ResolveShortnameToFQDN(h, c.ConfigMeta).Matches(svc.Hostname) {
+					ts.VirtualServices = append(ts.VirtualServices, c.Namespace+"/"+c.Name)
+					break
+				}
+			}
+		}
+		for _, c := range destinationRules {
+			dr := c.Spec.(*networking.DestinationRule)
+			if model.ResolveShortnameToFQDN(dr.Host, c.ConfigMeta).Matches(svc.Hostname) {
+				ts.DestinationRules = append(ts.DestinationRules, c.Namespace+"/"+c.Name)
+			}
+		}
+		topology.Services = append(topology.Services, ts)
+	}
+
+	out, err := json.MarshalIndent(topology, "", "  ")
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		_, _ = fmt.Fprintf(w, "unable to marshal topology: %v", err)
+		return
+	}
+	w.Header().Add("Content-Type", "application/json")
+	_, _ = w.Write(out)
+}
+
+func topologyQueryInt(req *http.Request, key string) (int, error) {
+	v := req.Form.Get(key)
+	if v == "" {
+		return 0, nil
+	}
+	i, err := strconv.Atoi(v)
+	if err != nil || i < 0 {
+		return 0, fmt.Errorf("invalid %s %q", key, v)
+	}
+	return i, nil
+}
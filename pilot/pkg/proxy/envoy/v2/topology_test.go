@@ -0,0 +1,115 @@
+// Copyright 2020 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v2
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/gogo/protobuf/proto"
+
+	networking "istio.io/api/networking/v1alpha3"
+
+	"istio.io/istio/pilot/pkg/model"
+	"istio.io/istio/pkg/config/schema/collection"
+	"istio.io/istio/pkg/config/schema/collections"
+)
+
+func topologyTestConfig(schema collection.Schema, name, namespace string, spec proto.Message) model.Config {
+	return model.Config{
+		ConfigMeta: model.ConfigMeta{
+			Type:              schema.Resource().Kind(),
+			Group:             schema.Resource().Group(),
+			Version:           schema.Resource().Version(),
+			Name:              name,
+			Namespace:         namespace,
+			CreationTimestamp: time.Now(),
+		},
+		Spec: spec,
+	}
+}
+
+func getTopology(t *testing.T, s *DiscoveryServer, query string) *Topology {
+	t.Helper()
+	req, err := http.NewRequest("GET", "/debug/topology"+query, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rr := httptest.NewRecorder()
+	http.HandlerFunc(s.Topologyz).ServeHTTP(rr, req)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("wanted response code 200, got %v: %s", rr.Code, rr.Body.String())
+	}
+	got := &Topology{}
+	if err := json.Unmarshal(rr.Body.Bytes(), got); err != nil {
+		t.Fatal(err)
+	}
+	return got
+}
+
+func TestTopologyz(t *testing.T) {
+	serviceEntry := func(name, namespace, hostname, address string) model.Config {
+		return topologyTestConfig(collections.IstioNetworkingV1Alpha3Serviceentries, name, namespace, &networking.ServiceEntry{
+			Hosts:      []string{hostname},
+			Ports:      []*networking.Port{{Number: 80, Name: "http", Protocol: "http"}},
+			Endpoints:  []*networking.ServiceEntry_Endpoint{{Address: address}},
+			Resolution: networking.ServiceEntry_STATIC,
+		})
+	}
+	s := SetupDiscoveryServer(t,
+		serviceEntry("foo", "default", "foo.example.com", "1.1.1.1"),
+		serviceEntry("bar", "default", "bar.example.com", "2.2.2.2"),
+		serviceEntry("baz", "other", "baz.example.com", "3.3.3.3"),
+		topologyTestConfig(collections.IstioNetworkingV1Alpha3Virtualservices, "foo-routes", "default", &networking.VirtualService{
+			Hosts: []string{"foo.example.com"},
+			Http:  []*networking.HTTPRoute{{Route: []*networking.HTTPRouteDestination{{Destination: &networking.Destination{Host: "foo.example.com"}}}}},
+		}),
+		topologyTestConfig(collections.IstioNetworkingV1Alpha3Destinationrules, "foo-policy", "default", &networking.DestinationRule{
+			Host: "foo.example.com",
+		}),
+	)
+
+	topology := getTopology(t, s, "?namespace=default")
+	var hostnames []string
+	for _, svc := range topology.Services {
+		hostnames = append(hostnames, string(svc.Hostname))
+	}
+	if want := []string{"bar.example.com", "foo.example.com"}; !reflect.DeepEqual(hostnames, want) {
+		t.Fatalf("got services %v, want %v", hostnames, want)
+	}
+
+	foo := topology.Services[1]
+	if len(foo.Instances) != 1 || foo.Instances[0].Address != "1.1.1.1" {
+		t.Errorf("got instances %v, want an instance at 1.1.1.1", foo.Instances)
+	}
+	if want := []string{"default/foo-routes"}; !reflect.DeepEqual(foo.VirtualServices, want) {
+		t.Errorf("got virtual services %v, want %v", foo.VirtualServices, want)
+	}
+	if want := []string{"default/foo-policy"}; !reflect.DeepEqual(foo.DestinationRules, want) {
+		t.Errorf("got destination rules %v, want %v", foo.DestinationRules, want)
+	}
+	if bar := topology.Services[0]; len(bar.VirtualServices) != 0 || len(bar.DestinationRules) != 0 {
+		t.Errorf("got routing config %v %v for bar.example.com, want none", bar.VirtualServices, bar.DestinationRules)
+	}
+
+	paged := getTopology(t, s, "?limit=1&offset=1")
+	if len(paged.Services) != 1 || paged.Services[0].Hostname != "baz.example.com" || paged.Next != 2 {
+		t.Errorf("got services %v and next %d, want baz.example.com and next 2", paged.Services, paged.Next)
+	}
+}
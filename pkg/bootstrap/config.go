@@ -95,6 +95,14 @@ type Config struct {
 	DisableReportCalls  bool
 	OutlierLogPath      string
 	PilotCertProvider   string
+
+	// EpochFileTemplate is the name template of the generated bootstrap file. It must contain a single %d verb
+	// for the epoch. Defaults to EpochFileTemplate.
+	EpochFileTemplate string
+
+	// OutputFormat is the format WriteTo writes the bootstrap in. If it differs from the format of the template, the
+	// rendered template is converted. Defaults to the format of the template. Epoch files are always JSON.
+	OutputFormat OutputFormat
 }
 
 // newTemplateParams creates a new template configuration for the given configuration.
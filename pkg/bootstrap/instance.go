@@ -15,14 +15,19 @@
 package bootstrap
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"io"
 	"io/ioutil"
 	"os"
 	"path"
+	"strings"
 	"text/template"
 
+	"github.com/ghodss/yaml"
+	"github.com/gogo/protobuf/proto"
+
 	"istio.io/pkg/log"
 
 	meshAPI "istio.io/api/mesh/v1alpha1"
@@ -40,13 +45,40 @@ var (
 	overrideVar = env.RegisterStringVar("ISTIO_BOOTSTRAP", "", "")
 )
 
+const redactedValue = "<redacted>"
+
+// OutputFormat is the format of a rendered bootstrap.
+type OutputFormat string
+
+const (
+	// OutputFormatJSON renders the bootstrap as JSON.
+	OutputFormatJSON OutputFormat = "json"
+	// OutputFormatYAML renders the bootstrap as YAML.
+	OutputFormatYAML OutputFormat = "yaml"
+)
+
+// formatForPath returns the format of a bootstrap template or file, based on its extension.
+func formatForPath(p string) OutputFormat {
+	switch path.Ext(p) {
+	case ".yaml", ".yml":
+		return OutputFormatYAML
+	default:
+		return OutputFormatJSON
+	}
+}
+
 // Instance of a configured Envoy bootstrap writer.
 type Instance interface {
 	// WriteTo writes the content of the Envoy bootstrap to the given writer.
 	WriteTo(w io.Writer) error
 
-	// CreateFileForEpoch generates an Envoy bootstrap file for a particular epoch.
+	// CreateFileForEpoch generates an Envoy bootstrap file for a particular epoch. The file is always written as
+	// JSON, converting YAML templates.
 	CreateFileForEpoch(epoch int) (string, error)
+
+	// WriteTemplateParamsTo writes the parameters used to render the Envoy bootstrap template to the given writer
+	// as JSON. Sensitive values are redacted.
+	WriteTemplateParamsTo(w io.Writer) error
 }
 
 // New creates a new Instance of an Envoy bootstrap writer.
@@ -61,8 +93,18 @@ type instance struct {
 }
 
 func (i *instance) WriteTo(w io.Writer) error {
+	return i.writeAs(w, i.OutputFormat)
+}
+
+// writeAs renders the bootstrap to w in the given format. An empty format keeps the format of the template.
+func (i *instance) writeAs(w io.Writer, format OutputFormat) error {
+	if err := ValidateOutputFormat(format); err != nil {
+		return err
+	}
+
 	// Get the input bootstrap template.
-	t, err := newTemplate(i.Proxy)
+	templateFilePath := getTemplateFilePath(i.Proxy)
+	t, err := newTemplateFromFile(templateFilePath)
 	if err != nil {
 		return err
 	}
@@ -73,8 +115,66 @@ func (i *instance) WriteTo(w io.Writer) error {
 		return err
 	}
 
-	// Execute the template.
-	return t.Execute(w, templateParams)
+	// Execute the template, converting the result if another format than the template's own is requested.
+	templateFormat := formatForPath(templateFilePath)
+	if format == "" || format == templateFormat {
+		return t.Execute(w, templateParams)
+	}
+	var out bytes.Buffer
+	if err := t.Execute(&out, templateParams); err != nil {
+		return err
+	}
+	converted, err := convertBootstrap(out.Bytes(), templateFormat, format)
+	if err != nil {
+		return fmt.Errorf("failed to convert bootstrap rendered from %s: %v", templateFilePath, err)
+	}
+	_, err = w.Write(converted)
+	return err
+}
+
+// convertBootstrap converts a rendered bootstrap between formats. The input must be well formed in its format.
+func convertBootstrap(rendered []byte, from, to OutputFormat) ([]byte, error) {
+	switch {
+	case from == OutputFormatJSON && to == OutputFormatYAML:
+		// JSONToYAML accepts any YAML, so check that the input is actually JSON.
+		if !json.Valid(rendered) {
+			return nil, fmt.Errorf("rendered bootstrap is not valid JSON")
+		}
+		return yaml.JSONToYAML(rendered)
+	case from == OutputFormatYAML && to == OutputFormatJSON:
+		return yaml.YAMLToJSON(rendered)
+	default:
+		return nil, fmt.Errorf("unsupported conversion from %s to %s", from, to)
+	}
+}
+
+func (i *instance) WriteTemplateParamsTo(w io.Writer) error {
+	templateParams, err := i.toTemplateParams()
+	if err != nil {
+		return err
+	}
+
+	redactTemplateParams(templateParams)
+
+	ba, err := json.MarshalIndent(templateParams, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(ba)
+	return err
+}
+
+// redactTemplateParams replaces secrets embedded in the template parameters with a placeholder.
+func redactTemplateParams(params map[string]interface{}) {
+	pc, ok := params["config"].(*meshAPI.ProxyConfig)
+	if !ok || pc == nil {
+		return
+	}
+	if ls, ok := pc.GetTracing().GetTracer().(*meshAPI.Tracing_Lightstep_); ok && ls.Lightstep.GetAccessToken() != "" {
+		pc = proto.Clone(pc).(*meshAPI.ProxyConfig)
+		pc.GetTracing().GetLightstep().AccessToken = redactedValue
+		params["config"] = pc
+	}
 }
 
 func toJSON(i interface{}) string {
@@ -92,30 +192,70 @@ func toJSON(i interface{}) string {
 }
 
 func (i *instance) CreateFileForEpoch(epoch int) (string, error) {
+	if err := ValidateEpochFileTemplate(i.EpochFileTemplate); err != nil {
+		return "", err
+	}
+
 	// Create the output file.
 	if err := os.MkdirAll(i.Proxy.ConfigPath, 0700); err != nil {
 		return "", err
 	}
-	outputFilePath := configFile(i.Proxy.ConfigPath, epoch)
+	outputFilePath := ConfigFile(i.Proxy.ConfigPath, i.EpochFileTemplate, epoch)
 	outputFile, err := os.Create(outputFilePath)
 	if err != nil {
 		return "", err
 	}
 	defer func() { _ = outputFile.Close() }()
 
-	// Write the content of the file.
-	if err := i.WriteTo(outputFile); err != nil {
+	// Write the content of the file. Envoy detects the format from the .json suffix, so YAML templates are converted.
+	if err := i.writeAs(outputFile, OutputFormatJSON); err != nil {
 		return "", err
 	}
 
 	return outputFilePath, err
 }
 
-func configFile(config string, epoch int) string {
-	return path.Join(config, fmt.Sprintf(EpochFileTemplate, epoch))
+// ConfigFile returns the path of the bootstrap file for the given epoch in the config directory. An empty
+// epochFileTemplate selects the default EpochFileTemplate.
+func ConfigFile(config, epochFileTemplate string, epoch int) string {
+	if epochFileTemplate == "" {
+		epochFileTemplate = EpochFileTemplate
+	}
+	return path.Join(config, fmt.Sprintf(epochFileTemplate, epoch))
+}
+
+// ValidateEpochFileTemplate checks that the bootstrap file name template contains a single epoch verb and keeps
+// the .json suffix, which Envoy uses to detect the bootstrap format. An empty template is valid and selects the
+// default EpochFileTemplate.
+func ValidateEpochFileTemplate(epochFileTemplate string) error {
+	if epochFileTemplate == "" {
+		return nil
+	}
+	if strings.Count(epochFileTemplate, "%") != 1 || !strings.Contains(epochFileTemplate, "%d") {
+		return fmt.Errorf("epoch file template %q must contain a single %%d verb for the epoch", epochFileTemplate)
+	}
+	if strings.ContainsRune(epochFileTemplate, '/') {
+		return fmt.Errorf("epoch file template %q must be a file name, not a path", epochFileTemplate)
+	}
+	if path.Ext(epochFileTemplate) != ".json" {
+		return fmt.Errorf("epoch file template %q must have a .json suffix", epochFileTemplate)
+	}
+	return nil
+}
+
+// ValidateOutputFormat checks that the bootstrap output format is known. An empty format is valid and keeps the
+// format of the template.
+func ValidateOutputFormat(format OutputFormat) error {
+	switch format {
+	case "", OutputFormatJSON, OutputFormatYAML:
+		return nil
+	default:
+		return fmt.Errorf("unknown bootstrap output format %q, must be %s or %s", format, OutputFormatJSON, OutputFormatYAML)
+	}
 }
 
-func newTemplate(config *meshAPI.ProxyConfig) (*template.Template, error) {
+// getTemplateFilePath returns the path of the bootstrap template selected by the proxy config.
+func getTemplateFilePath(config *meshAPI.ProxyConfig) string {
 	var templateFilePath string
 	switch {
 	case config.CustomConfigFile != "":
@@ -131,6 +271,10 @@ func newTemplate(config *meshAPI.ProxyConfig) (*template.Template, error) {
 		templateFilePath = override
 	}
 
+	return templateFilePath
+}
+
+func newTemplateFromFile(templateFilePath string) (*template.Template, error) {
 	cfgTmpl, err := ioutil.ReadFile(templateFilePath)
 	if err != nil {
 		return nil, err
@@ -14,6 +14,7 @@
 package bootstrap
 
 import (
+	"bytes"
 	"encoding/json"
 	"io/ioutil"
 	"os"
@@ -624,3 +625,244 @@ func (f *fakePlatform) Metadata() map[string]string {
 func (f *fakePlatform) Locality() *core.Locality {
 	return &core.Locality{}
 }
+
+func TestWriteTemplateParamsTo(t *testing.T) {
+	configPath, err := ioutil.TempDir("", "bootstrap-params")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = os.RemoveAll(configPath) }()
+
+	cfg := Config{
+		Node: "sidecar~1.2.3.4~foo~bar",
+		Proxy: &meshconfig.ProxyConfig{
+			ConfigPath:       configPath,
+			DiscoveryAddress: "istio-pilot:15010",
+			Tracing: &meshconfig.Tracing{
+				Tracer: &meshconfig.Tracing_Lightstep_{
+					Lightstep: &meshconfig.Tracing_Lightstep{
+						Address:     "lightstep-satellite:8080",
+						AccessToken: "secret-token",
+					},
+				},
+			},
+		},
+		PlatEnv: &fakePlatform{},
+	}
+
+	var b strings.Builder
+	if err := New(cfg).WriteTemplateParamsTo(&b); err != nil {
+		t.Fatal(err)
+	}
+	got := b.String()
+
+	params := make(map[string]interface{})
+	if err := json.Unmarshal([]byte(got), &params); err != nil {
+		t.Fatalf("template params are not valid JSON: %v\n%s", err, got)
+	}
+	if params["discovery_address"] != "istio-pilot:15010" {
+		t.Errorf("got discovery_address %v, want istio-pilot:15010", params["discovery_address"])
+	}
+	if !strings.Contains(got, configPath) {
+		t.Errorf("template params do not contain config path %s:\n%s", configPath, got)
+	}
+	if strings.Contains(got, "secret-token") {
+		t.Errorf("template params contain unredacted lightstep access token:\n%s", got)
+	}
+	if cfg.Proxy.GetTracing().GetLightstep().AccessToken != "secret-token" {
+		t.Errorf("redaction modified the input proxy config")
+	}
+}
+
+func TestConfigFile(t *testing.T) {
+	cases := []struct {
+		name     string
+		template string
+		want     string
+	}{
+		{
+			name: "default",
+			want: "/etc/istio/proxy/envoy-rev3.json",
+		},
+		{
+			name:     "custom",
+			template: "envoy-instance-2-rev%d.json",
+			want:     "/etc/istio/proxy/envoy-instance-2-rev3.json",
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if err := ValidateEpochFileTemplate(c.template); err != nil {
+				t.Fatal(err)
+			}
+			got := ConfigFile("/etc/istio/proxy", c.template, 3)
+			if got != c.want {
+				t.Errorf("got %s, want %s", got, c.want)
+			}
+			if path.Ext(got) != ".json" {
+				t.Errorf("got suffix %s, want .json", path.Ext(got))
+			}
+		})
+	}
+}
+
+func TestValidateEpochFileTemplate(t *testing.T) {
+	cases := []struct {
+		template string
+		wantErr  string
+	}{
+		{template: "envoy-rev.json", wantErr: "single %d verb"},
+		{template: "envoy-rev%d-%d.json", wantErr: "single %d verb"},
+		{template: "envoy-rev%s.json", wantErr: "single %d verb"},
+		{template: "bootstrap/envoy-rev%d.json", wantErr: "must be a file name"},
+		{template: "envoy-rev%d.yaml", wantErr: ".json suffix"},
+	}
+	for _, c := range cases {
+		t.Run(c.template, func(t *testing.T) {
+			err := ValidateEpochFileTemplate(c.template)
+			if err == nil || !strings.Contains(err.Error(), c.wantErr) {
+				t.Errorf("got error %v, want error containing %q", err, c.wantErr)
+			}
+		})
+	}
+}
+
+func TestCreateFileForEpochWithCustomTemplate(t *testing.T) {
+	configPath, err := ioutil.TempDir("", "bootstrap-epoch")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = os.RemoveAll(configPath) }()
+
+	gobase := os.Getenv("ISTIO_GO")
+	if gobase == "" {
+		gobase = "../.."
+	}
+	cfg := Config{
+		Node: "sidecar~1.2.3.4~foo~bar",
+		Proxy: &meshconfig.ProxyConfig{
+			ConfigPath:       configPath,
+			CustomConfigFile: gobase + "/tools/packaging/common/envoy_bootstrap_v2.json",
+		},
+		PlatEnv:           &fakePlatform{},
+		EpochFileTemplate: "envoy-instance-2-rev%d.json",
+	}
+
+	fn, err := New(cfg).CreateFileForEpoch(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := path.Join(configPath, "envoy-instance-2-rev1.json"); fn != want {
+		t.Errorf("got %s, want %s", fn, want)
+	}
+
+	cfg.EpochFileTemplate = "envoy-rev.json"
+	if _, err := New(cfg).CreateFileForEpoch(1); err == nil {
+		t.Error("expected error for epoch file template without epoch verb")
+	}
+}
+
+func TestWriteToOutputFormat(t *testing.T) {
+	dir, err := ioutil.TempDir("", "bootstrap-format")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	jsonTemplate := path.Join(dir, "bootstrap.json")
+	yamlTemplate := path.Join(dir, "bootstrap.yaml")
+	invalidTemplate := path.Join(dir, "invalid.json")
+	for file, content := range map[string]string{
+		jsonTemplate:    `{"node": {"id": "{{ .nodeID }}"}}`,
+		yamlTemplate:    "node:\n  id: {{ .nodeID }}\n",
+		invalidTemplate: `{"node": {{ .nodeID }}}`,
+	} {
+		if err := ioutil.WriteFile(file, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cases := []struct {
+		name     string
+		template string
+		format   OutputFormat
+		want     string
+		wantErr  bool
+	}{
+		{
+			name:     "json template keeps its format",
+			template: jsonTemplate,
+			want:     `{"node": {"id": "sidecar~1.2.3.4~foo~bar"}}`,
+		},
+		{
+			name:     "json to yaml",
+			template: jsonTemplate,
+			format:   OutputFormatYAML,
+			want:     "node:\n  id: sidecar~1.2.3.4~foo~bar\n",
+		},
+		{
+			name:     "yaml to json",
+			template: yamlTemplate,
+			format:   OutputFormatJSON,
+			want:     `{"node":{"id":"sidecar~1.2.3.4~foo~bar"}}`,
+		},
+		{
+			name:     "malformed json is not converted",
+			template: invalidTemplate,
+			format:   OutputFormatYAML,
+			wantErr:  true,
+		},
+		{
+			name:     "unknown format",
+			template: jsonTemplate,
+			format:   "toml",
+			wantErr:  true,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			var out bytes.Buffer
+			err := New(Config{
+				Node: "sidecar~1.2.3.4~foo~bar",
+				Proxy: &meshconfig.ProxyConfig{
+					ConfigPath:                 dir,
+					ProxyBootstrapTemplatePath: c.template,
+				},
+				PlatEnv:      &fakePlatform{},
+				OutputFormat: c.format,
+			}).WriteTo(&out)
+			if c.wantErr {
+				if err == nil {
+					t.Fatalf("expected error, got output %s", out.String())
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if out.String() != c.want {
+				t.Errorf("got %q, want %q", out.String(), c.want)
+			}
+		})
+	}
+
+	// Epoch files are always JSON, whatever the template and output format.
+	for _, format := range []OutputFormat{"", OutputFormatYAML} {
+		fn, err := New(Config{
+			Node:         "sidecar~1.2.3.4~foo~bar",
+			Proxy:        &meshconfig.ProxyConfig{ConfigPath: dir, ProxyBootstrapTemplatePath: yamlTemplate},
+			PlatEnv:      &fakePlatform{},
+			OutputFormat: format,
+		}).CreateFileForEpoch(1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := ioutil.ReadFile(fn)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := `{"node":{"id":"sidecar~1.2.3.4~foo~bar"}}`; string(got) != want {
+			t.Errorf("output format %q: got epoch file %q, want %q", format, got, want)
+		}
+	}
+}
@@ -0,0 +1,133 @@
+// Copyright 2020 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bootstrap
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/ghodss/yaml"
+
+	meshAPI "istio.io/api/mesh/v1alpha1"
+)
+
+// IssueKind is the class of a problem found in a bootstrap template.
+type IssueKind string
+
+const (
+	// IssueInvalidTemplate is reported when the template cannot be read or parsed.
+	IssueInvalidTemplate IssueKind = "InvalidTemplate"
+	// IssueUnknownFunction is reported when the template calls a function that is not defined.
+	IssueUnknownFunction IssueKind = "UnknownFunction"
+	// IssueUndefinedVariable is reported when the template uses an undefined variable or prints a parameter that
+	// is not set for the given config.
+	IssueUndefinedVariable IssueKind = "UndefinedVariable"
+	// IssueInvalidOutput is reported when the template fails to render or renders invalid JSON or YAML.
+	IssueInvalidOutput IssueKind = "InvalidOutput"
+	// IssueUnreachableDiscoveryAddress is reported when the discovery address of the config cannot be reached.
+	IssueUnreachableDiscoveryAddress IssueKind = "UnreachableDiscoveryAddress"
+)
+
+// Issue is a problem found by LintBootstrapTemplate.
+type Issue struct {
+	Kind    IssueKind
+	Message string
+}
+
+func (i Issue) String() string {
+	return fmt.Sprintf("%s: %s", i.Kind, i.Message)
+}
+
+// noValue is printed by text/template for parameters missing from the template parameter map.
+const noValue = "<no value>"
+
+// discoveryDialTimeout bounds the reachability check of the discovery address.
+var discoveryDialTimeout = 2 * time.Second
+
+// LintBootstrapTemplate renders the bootstrap template at templatePath with the given config and reports the
+// problems found, without starting a proxy. The config is rendered exactly as CreateFileForEpoch would, except that
+// the template path overrides the one selected by the proxy config.
+func LintBootstrapTemplate(templatePath string, cfg Config) []Issue {
+	if cfg.Proxy == nil {
+		cfg.Proxy = &meshAPI.ProxyConfig{}
+	}
+
+	var issues []Issue
+	issues = append(issues, lintDiscoveryAddress(cfg.Proxy.DiscoveryAddress)...)
+
+	t, err := newTemplateFromFile(templatePath)
+	if err != nil {
+		return append(issues, parseErrorIssue(err))
+	}
+
+	templateParams, err := cfg.toTemplateParams()
+	if err != nil {
+		return append(issues, Issue{Kind: IssueInvalidOutput, Message: fmt.Sprintf("unable to compute template parameters: %v", err)})
+	}
+
+	var out bytes.Buffer
+	if err := t.Execute(&out, templateParams); err != nil {
+		return append(issues, Issue{Kind: IssueInvalidOutput, Message: fmt.Sprintf("unable to render template: %v", err)})
+	}
+	rendered := out.Bytes()
+
+	if n := bytes.Count(rendered, []byte(noValue)); n > 0 {
+		issues = append(issues, Issue{
+			Kind:    IssueUndefinedVariable,
+			Message: fmt.Sprintf("template prints %d parameter(s) that are not set for this config", n),
+		})
+	}
+
+	var parsed interface{}
+	if formatForPath(templatePath) == OutputFormatYAML {
+		err = yaml.Unmarshal(rendered, &parsed)
+	} else {
+		err = json.Unmarshal(rendered, &parsed)
+	}
+	if err != nil {
+		issues = append(issues, Issue{Kind: IssueInvalidOutput, Message: fmt.Sprintf("rendered bootstrap is not well formed: %v", err)})
+	}
+
+	return issues
+}
+
+// parseErrorIssue classifies an error returned while reading or parsing a template.
+func parseErrorIssue(err error) Issue {
+	msg := err.Error()
+	switch {
+	case strings.Contains(msg, "not defined"):
+		return Issue{Kind: IssueUnknownFunction, Message: msg}
+	case strings.Contains(msg, "undefined variable"):
+		return Issue{Kind: IssueUndefinedVariable, Message: msg}
+	default:
+		return Issue{Kind: IssueInvalidTemplate, Message: msg}
+	}
+}
+
+func lintDiscoveryAddress(address string) []Issue {
+	if address == "" {
+		return []Issue{{Kind: IssueUnreachableDiscoveryAddress, Message: "discovery address is not set"}}
+	}
+	conn, err := net.DialTimeout("tcp", address, discoveryDialTimeout)
+	if err != nil {
+		return []Issue{{Kind: IssueUnreachableDiscoveryAddress, Message: fmt.Sprintf("discovery address %s: %v", address, err)}}
+	}
+	_ = conn.Close()
+	return nil
+}
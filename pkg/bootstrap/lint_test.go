@@ -0,0 +1,138 @@
+// Copyright 2020 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bootstrap
+
+import (
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	meshconfig "istio.io/api/mesh/v1alpha1"
+)
+
+func TestLintBootstrapTemplate(t *testing.T) {
+	// A listening socket makes the discovery address reachable.
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = l.Close() }()
+	reachable := l.Addr().String()
+
+	// A closed socket makes the discovery address unreachable.
+	closed, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	unreachable := closed.Addr().String()
+	_ = closed.Close()
+
+	dir, err := ioutil.TempDir("", "bootstrap-lint")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	cases := []struct {
+		name             string
+		file             string
+		template         string
+		discoveryAddress string
+		want             []IssueKind
+	}{
+		{
+			name:             "valid json",
+			file:             "valid.json",
+			template:         `{"node": {"id": "{{ .nodeID }}"}, "discovery": "{{ .discovery_address }}"}`,
+			discoveryAddress: reachable,
+		},
+		{
+			name:             "valid yaml",
+			file:             "valid.yaml",
+			template:         "node:\n  id: {{ .nodeID }}\n",
+			discoveryAddress: reachable,
+		},
+		{
+			name:             "unknown function",
+			file:             "func.json",
+			template:         `{"node": {{ toYAML .nodeID }}}`,
+			discoveryAddress: reachable,
+			want:             []IssueKind{IssueUnknownFunction},
+		},
+		{
+			name:             "undefined variable",
+			file:             "variable.json",
+			template:         `{"node": "{{ $node }}"}`,
+			discoveryAddress: reachable,
+			want:             []IssueKind{IssueUndefinedVariable},
+		},
+		{
+			name:             "unset parameter",
+			file:             "param.json",
+			template:         `{"node": "{{ .no_such_param }}"}`,
+			discoveryAddress: reachable,
+			want:             []IssueKind{IssueUndefinedVariable},
+		},
+		{
+			name:             "invalid json",
+			file:             "invalid.json",
+			template:         `{"node": {{ .nodeID }}}`,
+			discoveryAddress: reachable,
+			want:             []IssueKind{IssueInvalidOutput},
+		},
+		{
+			name:             "unreachable discovery address",
+			file:             "unreachable.json",
+			template:         `{"node": {"id": "{{ .nodeID }}"}}`,
+			discoveryAddress: unreachable,
+			want:             []IssueKind{IssueUnreachableDiscoveryAddress},
+		},
+		{
+			name:     "missing discovery address",
+			file:     "missing.json",
+			template: `{"node": {"id": "{{ .nodeID }}"}}`,
+			want:     []IssueKind{IssueUnreachableDiscoveryAddress},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			templatePath := filepath.Join(dir, c.file)
+			if err := ioutil.WriteFile(templatePath, []byte(c.template), 0600); err != nil {
+				t.Fatal(err)
+			}
+			cfg := Config{
+				Node: "sidecar~1.2.3.4~foo~bar",
+				Proxy: &meshconfig.ProxyConfig{
+					ConfigPath:       dir,
+					DiscoveryAddress: c.discoveryAddress,
+				},
+				PlatEnv: &fakePlatform{},
+			}
+
+			issues := LintBootstrapTemplate(templatePath, cfg)
+			if len(issues) != len(c.want) {
+				t.Fatalf("got issues %v, want kinds %v", issues, c.want)
+			}
+			for i, issue := range issues {
+				if issue.Kind != c.want[i] {
+					t.Errorf("got issue %v, want kind %v", issue, c.want[i])
+				}
+			}
+		})
+	}
+}
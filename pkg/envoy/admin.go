@@ -20,46 +20,115 @@ import (
 	"io"
 	"net/http"
 	"strings"
+	"time"
 
 	envoyAdmin "github.com/envoyproxy/go-control-plane/envoy/admin/v3"
 	"github.com/golang/protobuf/jsonpb"
 	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/ptypes"
 
+	"istio.io/pkg/env"
 	"istio.io/pkg/log"
+	"istio.io/pkg/version"
 )
 
+var adminRequestTimeout = env.RegisterDurationVar("ISTIO_ENVOY_ADMIN_TIMEOUT", 5*time.Second,
+	"Timeout of each request made to the Envoy admin API, except for config dumps").Get()
+
+var configDumpTimeout = env.RegisterDurationVar("ISTIO_ENVOY_CONFIG_DUMP_TIMEOUT", 2*time.Minute,
+	"Timeout of each config dump request made to the Envoy admin API. Config dumps of large meshes can take "+
+		"much longer than the other admin requests, so they have their own timeout.").Get()
+
+// adminClient is used for the small status and control requests to the Envoy admin API, so that a hung Envoy
+// cannot block the caller.
+var adminClient = &http.Client{Timeout: adminRequestTimeout}
+
+// configDumpClient is used for config dump requests, whose responses grow with the size of the mesh.
+var configDumpClient = &http.Client{Timeout: configDumpTimeout}
+
+// adminUserAgent identifies requests to the Envoy admin API in its access logs.
+var adminUserAgent = "istio-agent/" + version.Info.Version
+
 // Shutdown initiates a graceful shutdown of Envoy.
 func Shutdown(adminPort uint32) error {
-	_, err := doEnvoyPost("quitquitquit", "", "", adminPort)
+	_, err := doEnvoyPost(adminClient, "quitquitquit", "", "", adminPort)
 	return err
 }
 
 // DrainListeners drains inbound listeners of Envoy so that inflight requests
 // can gracefully finish and even continue making outbound calls as needed.
 func DrainListeners(adminPort uint32) error {
-	res, err := doEnvoyPost("drain_listeners?inboundonly", "", "", adminPort)
+	res, err := doEnvoyPost(adminClient, "drain_listeners?inboundonly", "", "", adminPort)
 	log.Debugf("Drain listener endpoint response : %s", res.String())
 	return err
 }
 
 // GetServerInfo returns a structure representing a call to /server_info
 func GetServerInfo(adminPort uint32) (*envoyAdmin.ServerInfo, error) {
-	buffer, err := doEnvoyGet("server_info", adminPort)
+	buffer, err := doEnvoyGet(adminClient, "server_info", adminPort)
 	if err != nil {
 		return nil, err
 	}
 
-	msg := &envoyAdmin.ServerInfo{}
-	if err := unmarshal(buffer.String(), msg); err != nil {
+	return parseServerInfo(buffer.Bytes())
+}
+
+// ServerState is the state of an Envoy server, as reported by the /server_info endpoint.
+type ServerState string
+
+// States of an Envoy server, named as in the admin API.
+const (
+	ServerLive            ServerState = "LIVE"
+	ServerDraining        ServerState = "DRAINING"
+	ServerPreInitializing ServerState = "PRE_INITIALIZING"
+	ServerInitializing    ServerState = "INITIALIZING"
+)
+
+// ServerInfo holds the fields of an Envoy /server_info response that are of interest to the agent.
+type ServerInfo struct {
+	State              ServerState
+	Version            string
+	UptimeCurrentEpoch time.Duration
+	UptimeAllEpochs    time.Duration
+	RestartEpoch       uint32
+}
+
+// ParseServerInfo parses the JSON response of the Envoy admin /server_info endpoint. Fields unknown to this version
+// of the admin API are ignored, so that responses of newer Envoy versions can be parsed too.
+func ParseServerInfo(b []byte) (*ServerInfo, error) {
+	msg, err := parseServerInfo(b)
+	if err != nil {
 		return nil, err
 	}
+	info := &ServerInfo{
+		State:        ServerState(msg.State.String()),
+		Version:      msg.Version,
+		RestartEpoch: msg.GetCommandLineOptions().GetRestartEpoch(),
+	}
+	if msg.UptimeCurrentEpoch != nil {
+		if info.UptimeCurrentEpoch, err = ptypes.Duration(msg.UptimeCurrentEpoch); err != nil {
+			return nil, fmt.Errorf("failed to parse server info: %v", err)
+		}
+	}
+	if msg.UptimeAllEpochs != nil {
+		if info.UptimeAllEpochs, err = ptypes.Duration(msg.UptimeAllEpochs); err != nil {
+			return nil, fmt.Errorf("failed to parse server info: %v", err)
+		}
+	}
+	return info, nil
+}
 
+func parseServerInfo(b []byte) (*envoyAdmin.ServerInfo, error) {
+	msg := &envoyAdmin.ServerInfo{}
+	if err := unmarshal(string(b), msg); err != nil {
+		return nil, fmt.Errorf("failed to parse server info: %v", err)
+	}
 	return msg, nil
 }
 
 // GetConfigDump polls Envoy admin port for the config dump and returns the response.
 func GetConfigDump(adminPort uint32) (*envoyAdmin.ConfigDump, error) {
-	buffer, err := doEnvoyGet("config_dump", adminPort)
+	buffer, err := doEnvoyGet(configDumpClient, "config_dump", adminPort)
 	if err != nil {
 		return nil, err
 	}
@@ -71,26 +140,31 @@ func GetConfigDump(adminPort uint32) (*envoyAdmin.ConfigDump, error) {
 	return msg, nil
 }
 
-func doEnvoyGet(path string, adminPort uint32) (*bytes.Buffer, error) {
+func doEnvoyGet(client *http.Client, path string, adminPort uint32) (*bytes.Buffer, error) {
 	requestURL := fmt.Sprintf("http://127.0.0.1:%d/%s", adminPort, path)
-	buffer, err := doHTTPGet(requestURL)
+	buffer, err := doHTTPGet(client, requestURL)
 	if err != nil {
 		return nil, err
 	}
 	return buffer, nil
 }
 
-func doEnvoyPost(path, contentType, body string, adminPort uint32) (*bytes.Buffer, error) {
+func doEnvoyPost(client *http.Client, path, contentType, body string, adminPort uint32) (*bytes.Buffer, error) {
 	requestURL := fmt.Sprintf("http://127.0.0.1:%d/%s", adminPort, path)
-	buffer, err := doHTTPPost(requestURL, contentType, body)
+	buffer, err := doHTTPPost(client, requestURL, contentType, body)
 	if err != nil {
 		return nil, err
 	}
 	return buffer, nil
 }
 
-func doHTTPGet(requestURL string) (*bytes.Buffer, error) {
-	response, err := http.Get(requestURL)
+func doHTTPGet(client *http.Client, requestURL string) (*bytes.Buffer, error) {
+	req, err := http.NewRequest(http.MethodGet, requestURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", adminUserAgent)
+	response, err := client.Do(req)
 	if err != nil {
 		return nil, err
 	}
@@ -107,8 +181,14 @@ func doHTTPGet(requestURL string) (*bytes.Buffer, error) {
 	return &b, nil
 }
 
-func doHTTPPost(requestURL, contentType, body string) (*bytes.Buffer, error) {
-	response, err := http.Post(requestURL, contentType, strings.NewReader(body))
+func doHTTPPost(client *http.Client, requestURL, contentType, body string) (*bytes.Buffer, error) {
+	req, err := http.NewRequest(http.MethodPost, requestURL, strings.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", contentType)
+	req.Header.Set("User-Agent", adminUserAgent)
+	response, err := client.Do(req)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,141 @@
+// Copyright 2018 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package envoy
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestParseServerInfo(t *testing.T) {
+	cases := []struct {
+		file               string
+		version            string
+		state              ServerState
+		uptimeCurrentEpoch time.Duration
+		uptimeAllEpochs    time.Duration
+		restartEpoch       uint32
+	}{
+		{
+			file:               "testdata/server_info_1.13.json",
+			version:            "73f240a29bece92a8882a36893ccce07b4a54664/1.13.1-dev/Clean/RELEASE/BoringSSL",
+			state:              ServerLive,
+			uptimeCurrentEpoch: 3605 * time.Second,
+			uptimeAllEpochs:    3605 * time.Second,
+		},
+		{
+			// Newer versions add fields unknown to this version of the admin API.
+			file:               "testdata/server_info_1.15.json",
+			version:            "f86f8f0b2f3a1b6e4f9e23a0a4f4e3f8d6c2b1a0/1.15.0/Clean/RELEASE/BoringSSL",
+			state:              ServerDraining,
+			uptimeCurrentEpoch: 12 * time.Second,
+			uptimeAllEpochs:    86412 * time.Second,
+			restartEpoch:       1,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.file, func(t *testing.T) {
+			b, err := ioutil.ReadFile(c.file)
+			if err != nil {
+				t.Fatal(err)
+			}
+			info, err := ParseServerInfo(b)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if info.Version != c.version {
+				t.Errorf("got version %q, want %q", info.Version, c.version)
+			}
+			if info.State != c.state {
+				t.Errorf("got state %v, want %v", info.State, c.state)
+			}
+			if info.UptimeCurrentEpoch != c.uptimeCurrentEpoch {
+				t.Errorf("got uptime of the current epoch %v, want %v", info.UptimeCurrentEpoch, c.uptimeCurrentEpoch)
+			}
+			if info.UptimeAllEpochs != c.uptimeAllEpochs {
+				t.Errorf("got uptime of all epochs %v, want %v", info.UptimeAllEpochs, c.uptimeAllEpochs)
+			}
+			if info.RestartEpoch != c.restartEpoch {
+				t.Errorf("got restart epoch %d, want %d", info.RestartEpoch, c.restartEpoch)
+			}
+		})
+	}
+}
+
+func TestParseServerInfoInvalid(t *testing.T) {
+	if _, err := ParseServerInfo([]byte(`{"state": 42`)); err == nil {
+		t.Error("expected an error parsing truncated server info")
+	}
+}
+
+func TestAdminRequestHeaders(t *testing.T) {
+	var got []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = append(got, r.Method+" "+r.UserAgent())
+	}))
+	defer srv.Close()
+
+	if _, err := doHTTPGet(adminClient, srv.URL+"/server_info"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := doHTTPPost(adminClient, srv.URL+"/quitquitquit", "", ""); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"GET " + adminUserAgent, "POST " + adminUserAgent}
+	if len(got) != len(want) || got[0] != want[0] || got[1] != want[1] {
+		t.Errorf("got requests %v, want %v", got, want)
+	}
+}
+
+func TestAdminRequestTimeout(t *testing.T) {
+	done := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-done
+	}))
+	defer srv.Close()
+	defer close(done)
+
+	timeout := adminClient.Timeout
+	adminClient.Timeout = 10 * time.Millisecond
+	defer func() { adminClient.Timeout = timeout }()
+
+	if _, err := doHTTPGet(adminClient, srv.URL+"/server_info"); err == nil {
+		t.Fatal("expected the request to a hung admin endpoint to time out")
+	}
+}
+
+func TestConfigDumpTimeout(t *testing.T) {
+	// Config dumps of large meshes must not be cut off by the timeout of the status requests.
+	if configDumpClient.Timeout <= adminClient.Timeout {
+		t.Errorf("got config dump timeout %v, want more than the admin timeout %v", configDumpClient.Timeout, adminClient.Timeout)
+	}
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(50 * time.Millisecond)
+		_, _ = w.Write([]byte("{}"))
+	}))
+	defer srv.Close()
+
+	timeout := adminClient.Timeout
+	adminClient.Timeout = 10 * time.Millisecond
+	defer func() { adminClient.Timeout = timeout }()
+
+	if _, err := doHTTPGet(configDumpClient, srv.URL+"/config_dump"); err != nil {
+		t.Errorf("config dump request failed: %v", err)
+	}
+}
@@ -20,7 +20,6 @@ import (
 	"net"
 	"os"
 	"os/exec"
-	"path"
 	"time"
 
 	envoyAdmin "github.com/envoyproxy/go-control-plane/envoy/admin/v3"
@@ -33,11 +32,6 @@ import (
 	"istio.io/istio/pkg/bootstrap"
 )
 
-const (
-	// epochFileTemplate is a template for the root config JSON
-	epochFileTemplate = "envoy-rev%d.json"
-)
-
 type envoy struct {
 	ProxyConfig
 	extraArgs []string
@@ -61,6 +55,7 @@ type ProxyConfig struct {
 	DisableReportCalls  bool
 	OutlierLogPath      string
 	PilotCertProvider   string
+	EpochFileTemplate   string
 }
 
 // NewProxy creates an instance of the proxy control commands
@@ -168,6 +163,7 @@ func (e *envoy) Run(config interface{}, epoch int, abort <-chan error) error {
 			DisableReportCalls:  e.DisableReportCalls,
 			OutlierLogPath:      e.OutlierLogPath,
 			PilotCertProvider:   e.PilotCertProvider,
+			EpochFileTemplate:   e.EpochFileTemplate,
 		}).CreateFileForEpoch(epoch)
 		if err != nil {
 			log.Errora("Failed to generate bootstrap config: ", err)
@@ -205,7 +201,7 @@ func (e *envoy) Run(config interface{}, epoch int, abort <-chan error) error {
 }
 
 func (e *envoy) Cleanup(epoch int) {
-	filePath := configFile(e.Config.ConfigPath, epoch)
+	filePath := bootstrap.ConfigFile(e.Config.ConfigPath, e.EpochFileTemplate, epoch)
 	if err := os.Remove(filePath); err != nil {
 		log.Warnf("Failed to delete config file %s for %d, %v", filePath, epoch, err)
 	}
@@ -223,10 +219,6 @@ func convertDuration(d *types.Duration) time.Duration {
 	return dur
 }
 
-func configFile(config string, epoch int) string {
-	return path.Join(config, fmt.Sprintf(epochFileTemplate, epoch))
-}
-
 // isIPv6Proxy check the addresses slice and returns true for a valid IPv6 address
 // for all other cases it returns false
 func isIPv6Proxy(ipAddrs []string) bool {